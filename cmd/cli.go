@@ -0,0 +1,261 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/nlypage/BankSystemVisualize/contagion"
+	"github.com/nlypage/BankSystemVisualize/locale"
+	"github.com/nlypage/BankSystemVisualize/report"
+	"github.com/nlypage/BankSystemVisualize/scenario"
+	"github.com/nlypage/BankSystemVisualize/trace"
+)
+
+// options общие флаги подкоманд: файл сценария и переопределения его параметров
+type options struct {
+	flags *flag.FlagSet
+
+	scenarioPath string
+	edgesPath    string
+	balancesPath string
+	matrixPath   string
+	trigger      string
+	lambda       float64
+	lambdaC      float64
+	lambdaF      float64
+	panicRate    float64
+	enablePanic  bool
+	capitalRatio float64
+	model        string
+	order        string
+	update       string
+	seed         int64
+	lang         string
+}
+
+// newFlagSet создает набор флагов подкоманды с общими флагами сценария
+func newFlagSet(name string) (*flag.FlagSet, *options) {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	opts := &options{flags: fs}
+	fs.StringVar(&opts.scenarioPath, "scenario", "", "файл сценария в формате JSON (по умолчанию встроенное кольцо из 5 банков)")
+	fs.StringVar(&opts.edgesPath, "edges", "", "CSV со списком требований from,to,amount вместо банков сценария")
+	fs.StringVar(&opts.balancesPath, "balances", "", "CSV с балансами bank,balance для списка требований")
+	fs.StringVar(&opts.matrixPath, "matrix", "", "CSV с матрицей требований и столбцом balance вместо банков сценария")
+	fs.StringVar(&opts.trigger, "trigger", "", "банки, объявляемые банкротами, через запятую (переопределяет шок сценария)")
+	fs.Float64Var(&opts.lambda, "lambda", 0, "lambda для кредитного шока и шока фондирования")
+	fs.Float64Var(&opts.lambdaC, "lambda-c", 0, "lambda для кредитного шока")
+	fs.Float64Var(&opts.lambdaF, "lambda-f", 0, "lambda для шока фондирования")
+	fs.Float64Var(&opts.panicRate, "p", 0, "доля вкладов, закрываемых при набеге")
+	fs.BoolVar(&opts.enablePanic, "panic", true, "включить набег вкладчиков")
+	fs.Float64Var(&opts.capitalRatio, "capital-ratio", 0, "доля капитального буфера в активах банка (модель Гаи–Кападиа)")
+	fs.StringVar(&opts.model, "model", "", "модель распространения: cascade, eisenberg-noe или debtrank")
+	fs.StringVar(&opts.order, "order", "", "порядок обработки банков: name, exposure или random")
+	fs.StringVar(&opts.update, "update", "", "режим применения шоков: sequential, synchronous или immediate")
+	fs.Int64Var(&opts.seed, "seed", 0, "зерно генератора случайных чисел")
+	fs.StringVar(&opts.lang, "lang", "", "язык сообщений и отчетов: ru или en (по умолчанию из переменной окружения "+locale.EnvVar+", иначе ru)")
+	return fs, opts
+}
+
+// isSet сообщает, был ли флаг явно указан в командной строке
+func (o *options) isSet(name string) bool {
+	set := false
+	o.flags.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// load читает сценарий из файла (или берет встроенный), подставляет сеть из CSV и применяет флаги
+func (o *options) load() (*scenario.Scenario, error) {
+	sc := defaultScenario()
+	if o.scenarioPath != "" {
+		var err error
+		if sc, err = scenario.Load(o.scenarioPath); err != nil {
+			return nil, err
+		}
+	}
+	if err := o.importNetwork(sc); err != nil {
+		return nil, err
+	}
+	o.apply(sc)
+	return sc, nil
+}
+
+// messages возвращает каталог сообщений выбранного языка
+func (o *options) messages() (*locale.Catalog, error) {
+	return locale.New(o.lang)
+}
+
+// hasNetwork сообщает, задана ли сеть банков файлом сценария или CSV
+func (o *options) hasNetwork() bool {
+	return o.scenarioPath != "" || o.edgesPath != "" || o.matrixPath != ""
+}
+
+// importNetwork заменяет банки сценария сетью требований из CSV, если она указана
+func (o *options) importNetwork(sc *scenario.Scenario) error {
+	var banks map[string]scenario.BankSpec
+	var err error
+	switch {
+	case o.edgesPath != "" && o.matrixPath != "":
+		return fmt.Errorf("флаги -edges и -matrix нельзя указывать вместе")
+	case o.edgesPath != "":
+		banks, err = scenario.LoadEdgeList(o.edgesPath, o.balancesPath)
+	case o.matrixPath != "":
+		banks, err = scenario.LoadMatrix(o.matrixPath)
+	case o.balancesPath != "":
+		return fmt.Errorf("флаг -balances используется только вместе с -edges")
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	sc.Topology = nil
+	sc.Banks = banks
+	return nil
+}
+
+// apply переопределяет параметры и шок сценария явно указанными флагами
+func (o *options) apply(sc *scenario.Scenario) {
+	params := &sc.Parameters
+	if o.isSet("lambda") {
+		params.LambdaC, params.LambdaF = o.lambda, o.lambda
+	}
+	if o.isSet("lambda-c") {
+		params.LambdaC = o.lambdaC
+	}
+	if o.isSet("lambda-f") {
+		params.LambdaF = o.lambdaF
+	}
+	if o.isSet("p") {
+		params.PanicRate = o.panicRate
+	}
+	if o.isSet("panic") {
+		params.EnablePanic = o.enablePanic
+	}
+	if o.isSet("capital-ratio") {
+		params.CapitalRatio = o.capitalRatio
+	}
+	if o.isSet("model") {
+		params.Model = o.model
+	}
+	if o.isSet("order") {
+		params.Order = o.order
+	}
+	if o.isSet("update") {
+		params.Update = o.update
+	}
+	if o.isSet("seed") {
+		params.Seed = o.seed
+	}
+	if o.isSet("trigger") {
+		sc.Shock = scenario.Shock{Banks: splitNames(o.trigger)}
+	}
+}
+
+// splitNames разбивает список имен через запятую, убирая пробелы и пустые имена
+func splitNames(list string) []string {
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// openTrace подписывает на систему запись трассы в файл path. Возвращаемая функция
+// закрывает файл и сообщает о первой ошибке записи
+func openTrace(path string, system *contagion.BankSystem) (func() error, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	tracer := trace.New(file, system)
+	system.Subscribe(tracer)
+
+	return func() error {
+		if err := tracer.Err(); err != nil {
+			file.Close()
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		return file.Close()
+	}, nil
+}
+
+// writeReport записывает итоги стресс-теста в файл path, формат определяется по расширению
+func writeReport(path string, collector *report.Collector, sc *scenario.Scenario, messages *locale.Catalog) error {
+	title := messages.T("report.title")
+	if sc.Name != "" {
+		title += ": " + sc.Name
+	}
+	result := collector.Report(title)
+	result.Messages = messages
+	result.Parameters = append(result.Parameters, report.Parameter{Name: "shock", Value: shockDescription(sc.Shock)})
+
+	// Отчет собирается в памяти, чтобы при неизвестном формате не оставлять пустой файл
+	var buf bytes.Buffer
+	if err := result.Write(&buf, report.FormatOf(path)); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// shockDescription описывает начальный шок сценария одной строкой
+func shockDescription(shock scenario.Shock) string {
+	parts := append([]string(nil), shock.Banks...)
+	names := make([]string, 0, len(shock.Losses))
+	for name := range shock.Losses {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s -%.2f", name, shock.Losses[name]))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// defaultScenario встроенный сценарий: кольцо из 5 банков, банкротом объявляется банк 1
+func defaultScenario() *scenario.Scenario {
+	return &scenario.Scenario{
+		Topology: &scenario.Topology{
+			Kind:     "ring",
+			Banks:    5,
+			Balance:  1000, // Баланс каждого банка
+			Exposure: 5000, // Сумма задолженности каждого банка
+		},
+		Parameters: scenario.Parameters{
+			LambdaC:     0.5,
+			LambdaF:     0.5,
+			PanicRate:   0.7, // Процент от вклада который заберет банк при набеге
+			EnablePanic: true,
+			Model:       "cascade",
+			Order:       "name",
+		},
+		Shock: scenario.Shock{Banks: []string{"1"}},
+	}
+}
+
+// usage печатает список подкоманд
+func usage() {
+	fmt.Fprintf(os.Stderr, `Использование: %s <подкоманда> [флаги]
+
+Подкоманды:
+  visualize   пошаговая визуализация стресс-теста (по умолчанию); в сборке с -tags headless окна нет,
+              запись в GIF (-gif) и PNG-кадры (-frames) работают в любой сборке
+  run         стресс-тест без окна
+  sweep       перебор сетки параметров
+  rank        рейтинг системной значимости банков
+  montecarlo  моделирование коррелированных начальных шоков методом Монте-Карло
+  export      запись сценария с явным списком банков в JSON
+
+Флаги подкоманды: %s <подкоманда> -h
+`, os.Args[0], os.Args[0])
+}
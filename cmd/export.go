@@ -0,0 +1,38 @@
+package main
+
+import (
+	"io"
+	"log"
+	"os"
+)
+
+// exportCommand записывает сценарий с примененными флагами и явным списком банков,
+// например чтобы превратить сгенерированную топологию в редактируемый файл
+func exportCommand(args []string) {
+	fs, opts := newFlagSet("export")
+	output := fs.String("o", "", "файл для записи сценария (по умолчанию стандартный вывод)")
+	fs.Parse(args)
+
+	sc, err := opts.load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	expanded, err := sc.Expand()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if err := expanded.Write(w); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/nlypage/BankSystemVisualize/montecarlo"
+)
+
+// montecarloCommand оценивает распределение числа банкротств при коррелированных начальных шоках
+func montecarloCommand(args []string) {
+	fs, opts := newFlagSet("montecarlo")
+	runs := fs.Int("runs", 10000, "количество прогонов")
+	probability := fs.Float64("pd", 0.05, "вероятность начального дефолта каждого банка")
+	lossMin := fs.Float64("loss-min", 0, "минимальная доля потерь баланса при начальном шоке")
+	lossMax := fs.Float64("loss-max", 0.5, "максимальная доля потерь баланса при начальном шоке")
+	correlation := fs.Float64("correlation", 0.3, "корреляция шоков в однофакторной модели")
+	fs.Parse(args)
+
+	sc, err := opts.load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	system, err := sc.System()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	seed := int64(1)
+	if opts.isSet("seed") {
+		seed = opts.seed
+	}
+
+	result, err := montecarlo.Run(system, montecarlo.Config{
+		Runs:               *runs,
+		Seed:               seed,
+		DefaultProbability: *probability,
+		Loss:               montecarlo.Uniform{Min: *lossMin, Max: *lossMax},
+		Correlation:        *correlation,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("runs: %d, mean defaults: %.3f, mean loss: %.2f, loss VaR 99%%: %.2f\n",
+		len(result.DefaultCounts), result.MeanDefaults(), result.MeanLoss(), result.LossQuantile(0.99))
+
+	distribution := result.CountDistribution()
+	counts := make([]int, 0, len(distribution))
+	for count := range distribution {
+		counts = append(counts, count)
+	}
+	sort.Ints(counts)
+	for _, count := range counts {
+		fmt.Printf("defaults: %d, probability: %.4f\n", count, distribution[count])
+	}
+
+	names := make([]string, 0, len(result.DefaultProbability))
+	for name := range result.DefaultProbability {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("bank: %s, default probability: %.4f\n", name, result.DefaultProbability[name])
+	}
+}
@@ -0,0 +1,13 @@
+//go:build headless
+
+package main
+
+import "github.com/nlypage/BankSystemVisualize/render"
+
+// windowSupported сообщает, что окно визуализатора в эту сборку не входит
+const windowSupported = false
+
+// runWindow в сборке с тегом headless только возвращает ошибку
+func runWindow(*render.Scene, string, []string, map[string]float64, func()) error {
+	return errNoWindow
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+	"log"
+)
+
+// rankCommand печатает рейтинг системной значимости банков и DebtRank каждого банка
+func rankCommand(args []string) {
+	fs, opts := newFlagSet("rank")
+	fs.Parse(args)
+
+	sc, err := opts.load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	system, err := sc.System()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// DebtRank каждого банка при его полном начальном дистрессе, независимо от выбранной модели
+	debtRanks := system.DebtRanks()
+
+	fmt.Printf("%-6s %-8s %-10s %-12s %s\n", "rank", "bank", "defaults", "loss", "debtrank")
+	for _, importance := range system.RankImportance() {
+		fmt.Printf("%-6d %-8s %-10d %-12.2f %.4f\n", importance.Rank, importance.Bank, importance.Defaults, importance.Loss, debtRanks[importance.Bank])
+	}
+}
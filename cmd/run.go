@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+
+	"github.com/nlypage/BankSystemVisualize/contagion"
+	"github.com/nlypage/BankSystemVisualize/report"
+)
+
+// runCommand запускает стресс-тест сценария без окна и печатает итог
+func runCommand(args []string) {
+	fs, opts := newFlagSet("run")
+	tracePath := fs.String("trace", "", "файл для записи трассы событий в формате JSON Lines")
+	reportPath := fs.String("report", "", "файл для итогового отчета: .csv, .md или .html")
+	fs.Parse(args)
+
+	sc, err := opts.load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	messages, err := opts.messages()
+	if err != nil {
+		log.Fatal(err)
+	}
+	system, err := sc.System()
+	if err != nil {
+		log.Fatal(err)
+	}
+	initial := system.Clone().Banks
+
+	closeTrace := func() error { return nil }
+	if *tracePath != "" {
+		if closeTrace, err = openTrace(*tracePath, system); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	collector := report.NewCollector(system)
+	system.Subscribe(collector)
+
+	defaults := system.Shock(sc.Shock.Banks, sc.Shock.Losses)
+	// Трасса закрывается сразу после стресс-теста: log.Fatal ниже не выполняет отложенные вызовы
+	if err := closeTrace(); err != nil {
+		log.Fatal(err)
+	}
+	if *reportPath != "" {
+		if err := writeReport(*reportPath, collector, sc, messages); err != nil {
+			log.Fatal(err)
+		}
+	}
+	fmt.Printf("model: %s, order: %s, update: %s\n", system.Model, system.Order, system.Update)
+	fmt.Printf("defaults: %d, loss: %.2f\n", defaults, system.TotalLoss(initial))
+	if system.Clearing != nil {
+		printClearing(system.Clearing)
+	}
+	if system.DebtRankResult != nil {
+		printDebtRank(system.DebtRankResult)
+	}
+}
+
+// printDebtRank печатает групповой DebtRank и итоговый дистресс каждого банка
+func printDebtRank(result *contagion.DebtRankResult) {
+	fmt.Printf("debtrank: %.4f, rounds: %d\n", result.Rank, result.Rounds)
+
+	names := make([]string, 0, len(result.States))
+	for name := range result.States {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("bank: %s, distress: %.4f, state: %s\n", name, result.Distress[name], result.States[name])
+	}
+}
+
+// printClearing печатает клиринговый вектор Айзенберга–Ноэ и суммы, полученные кредиторами от банкротов
+func printClearing(clearing *contagion.ClearingResult) {
+	names := make([]string, 0, len(clearing.Payments))
+	for name := range clearing.Payments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		round := "-"
+		if r, exists := clearing.DefaultRound[name]; exists {
+			round = strconv.Itoa(r)
+		}
+		fmt.Printf("bank: %s, liabilities: %.2f, payment: %.2f, recovery rate: %.4f, default round: %s\n",
+			name, clearing.Liabilities[name], clearing.Payments[name], clearing.RecoveryRate(name), round)
+	}
+	for _, debtor := range clearing.Defaulted {
+		for _, creditor := range names {
+			if recovered, exists := clearing.Recovery[creditor][debtor]; exists {
+				fmt.Printf("creditor: %s, debtor: %s, recovered: %.2f\n", creditor, debtor, recovered)
+			}
+		}
+	}
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/nlypage/BankSystemVisualize/contagion"
+	"github.com/nlypage/BankSystemVisualize/locale"
+	"github.com/nlypage/BankSystemVisualize/plot"
+	"github.com/nlypage/BankSystemVisualize/scenario"
+	"github.com/nlypage/BankSystemVisualize/sweep"
+)
+
+// sweepCommand перебирает сетку параметров для полной и кольцевой топологий или для сценария из файла
+func sweepCommand(args []string) {
+	fs, opts := newFlagSet("sweep")
+	boundary := fs.String("boundary", "", "вместо перебора сетки искать границу режимов: lambda (критическое lambda для каждого p) или p")
+	precision := fs.Float64("precision", 0.001, "точность поиска критического значения")
+	steps := fs.Int("steps", 10, "количество шагов по второму параметру при построении границы")
+	csvPath := fs.String("csv", "", "файл для записи полных результатов перебора в CSV")
+	workers := fs.Int("workers", 0, "количество параллельных обработчиков, 0 - по числу ядер")
+	heatmapPath := fs.String("heatmap", "", "файл для фазовой диаграммы в формате PNG")
+	metric := fs.String("metric", "diff", "метрика диаграммы: diff (full минус ring) или имя топологии")
+	xAxis := fs.String("x", "lambda", "ось сетки по горизонтали диаграммы")
+	yAxis := fs.String("y", "p", "ось сетки по вертикали диаграммы")
+	banks := fs.Int("banks", 5, "количество банков в полной и кольцевой топологиях")
+	var axes axisFlags
+	fs.Var(&axes, "axis", "ось сетки имя=начало:конец:шаг или имя=значение,значение (можно повторять); "+
+		"параметры: p, lambda, lambda_c, lambda_f, capital_ratio, seed, shock. По умолчанию capital_ratio, p и lambda")
+	fs.Parse(args)
+
+	messages, err := opts.messages()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	X := 1000.0 // Баланс каждого банка
+	Y := 5000.0 // Сумма задолженности каждого банка
+
+	// Без сценария или сети из CSV сравниваются полная и кольцевая топологии.
+	// Порядок и режим применения шоков фиксируются для воспроизводимости
+	scenarios := map[string]*scenario.Scenario{}
+	if opts.hasNetwork() {
+		sc, err := opts.load()
+		if err != nil {
+			log.Fatal(err)
+		}
+		name := sc.Name
+		if name == "" {
+			name = "scenario"
+		}
+		scenarios[name] = sc
+		if *metric == "diff" {
+			*metric = name
+		}
+	} else {
+		for name, kind := range map[string]string{"full": "complete", "ring": "ring"} {
+			sc := &scenario.Scenario{
+				Topology:   &scenario.Topology{Kind: kind, Banks: *banks, Balance: X, Exposure: Y},
+				Parameters: scenario.Parameters{EnablePanic: true, Order: "name", Update: "sequential"},
+				Shock:      scenario.Shock{Banks: []string{"1"}},
+			}
+			opts.apply(sc)
+			scenarios[name] = sc
+		}
+	}
+
+	// Перебор запускается от одного банка-триггера, им считается первый банк шока
+	topologies := make(map[string]*contagion.BankSystem, len(scenarios))
+	trigger := ""
+	for name, sc := range scenarios {
+		system, err := sc.System()
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(sc.Shock.Banks) == 0 {
+			log.Fatalf("%s: в шоке сценария нет банков для стресс-теста", name)
+		}
+		topologies[name] = system
+		trigger = sc.Shock.Banks[0]
+	}
+	first := topologies[topologyNames(topologies)[0]]
+	order, update := first.Order, first.Update
+
+	if *boundary != "" {
+		if *boundary != "lambda" && *boundary != "p" {
+			log.Fatalf("-boundary должен быть lambda или p, получено %q", *boundary)
+		}
+		if *steps <= 0 {
+			log.Fatalf("-steps должен быть положительным, получено %d", *steps)
+		}
+		if *precision <= 0 {
+			log.Fatalf("-precision должна быть положительной, получено %g", *precision)
+		}
+		for _, name := range topologyNames(topologies) {
+			printBoundary(*boundary, name, topologies[name], trigger, *steps, *precision)
+		}
+		return
+	}
+
+	// Сетка параметров по умолчанию: доли капитального буфера модели Гаи–Кападиа
+	// (ноль - банкротство при отрицательном балансе), p и lambda
+	grid := []sweep.Axis(axes)
+	if len(grid) == 0 {
+		grid = []sweep.Axis{
+			{Name: "capital_ratio", Values: []float64{0, 0.02, 0.04, 0.06, 0.08, 0.1}},
+			sweep.Range("p", 0.1, 1.0, 9),
+			sweep.Range("lambda", 0.1, 1.0, 9),
+		}
+	}
+	if err := checkAxisFlags(opts, grid); err != nil {
+		log.Fatal(err)
+	}
+	// Оси и метрика диаграммы проверяются до перебора, чтобы не считать сетку впустую
+	if *heatmapPath != "" {
+		if err := checkHeatmap(grid, topologies, *xAxis, *yAxis, *metric); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	results, err := sweep.Run(sweep.Config{
+		Grid:       grid,
+		Topologies: topologies,
+		Trigger:    trigger,
+		Workers:    *workers,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *csvPath != "" {
+		file, err := os.Create(*csvPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+
+		if err := sweep.WriteCSV(file, grid, results); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if *heatmapPath != "" {
+		if err := writeHeatmap(*heatmapPath, *metric, *xAxis, *yAxis, grid, results, messages); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	fmt.Printf("order: %s, update: %s\n", order, update)
+	if opts.hasNetwork() {
+		for _, result := range results {
+			fmt.Printf("topology: %s, %s, defaults: %d\n", result.Topology, formatPoint(grid, result.Params), result.Defaults)
+		}
+		return
+	}
+
+	// Результаты идут парами по точкам сетки: full, затем ring
+	for i := 0; i < len(results); i += 2 {
+		full, ring := results[i], results[i+1]
+		if ring.Defaults < full.Defaults {
+			fmt.Println(formatPoint(grid, full.Params))
+		}
+	}
+}
+
+// axisFlags значения повторяемого флага -axis
+type axisFlags []sweep.Axis
+
+// String возвращает имена осей через запятую
+func (a *axisFlags) String() string {
+	names := make([]string, len(*a))
+	for i, axis := range *a {
+		names[i] = axis.Name
+	}
+	return strings.Join(names, ",")
+}
+
+// Set добавляет ось из значения флага
+func (a *axisFlags) Set(value string) error {
+	axis, err := sweep.ParseAxis(value)
+	if err != nil {
+		return err
+	}
+	*a = append(*a, axis)
+	return nil
+}
+
+// axisFlagNames общие флаги, которые задают тот же параметр, что и ось сетки
+var axisFlagNames = map[string][]string{
+	"p":             {"p"},
+	"lambda":        {"lambda", "lambda-c", "lambda-f"},
+	"lambda_c":      {"lambda", "lambda-c"},
+	"lambda_f":      {"lambda", "lambda-f"},
+	"capital_ratio": {"capital-ratio"},
+	"seed":          {"seed"},
+}
+
+// checkAxisFlags запрещает общие флаги, значения которых перебор заменил бы значениями оси
+func checkAxisFlags(opts *options, grid []sweep.Axis) error {
+	for _, axis := range grid {
+		for _, name := range axisFlagNames[axis.Name] {
+			if opts.isSet(name) {
+				return fmt.Errorf("флаг -%s конфликтует с осью сетки %s: задайте значения через -axis %s=...", name, axis.Name, axis.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// formatPoint печатает значения параметров точки сетки
+func formatPoint(grid []sweep.Axis, params []float64) string {
+	parts := make([]string, len(grid))
+	for i, axis := range grid {
+		parts[i] = fmt.Sprintf("%s: %f", axis.Name, params[i])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// checkHeatmap проверяет, что оси диаграммы различны и есть в сетке, а метрика - топология перебора
+// или diff для пары full и ring
+func checkHeatmap(grid []sweep.Axis, topologies map[string]*contagion.BankSystem, x, y, metric string) error {
+	if x == y {
+		return fmt.Errorf("оси диаграммы совпадают: %s", x)
+	}
+	for _, name := range []string{x, y} {
+		found := false
+		for _, axis := range grid {
+			found = found || axis.Name == name
+		}
+		if !found {
+			return fmt.Errorf("оси диаграммы %s нет в сетке: задайте ее через -axis %s=... или выберите другую флагами -x и -y", name, name)
+		}
+	}
+
+	required := []string{metric}
+	if metric == "diff" {
+		required = []string{"full", "ring"}
+	}
+	for _, name := range required {
+		if _, exists := topologies[name]; !exists {
+			return fmt.Errorf("неизвестная метрика диаграммы %q: ожидается diff или одна из топологий %s",
+				metric, strings.Join(topologyNames(topologies), ", "))
+		}
+	}
+	return nil
+}
+
+// writeHeatmap рисует фазовую диаграмму по осям x и y. Остальные оси сетки берутся в первом значении,
+// для сетки по умолчанию это нулевой капитальный буфер
+func writeHeatmap(path, metric, x, y string, grid []sweep.Axis, results []sweep.Result, messages *locale.Catalog) error {
+	defaults := func(r sweep.Result) float64 {
+		return float64(r.Defaults)
+	}
+
+	var values [][]float64
+	var xs, ys []float64
+	title := ""
+	switch metric {
+	default:
+		var err error
+		xs, ys, values, err = sweep.Matrix(grid, results, metric, x, y, nil, defaults)
+		if err != nil {
+			return err
+		}
+		title = messages.T("heatmap.defaults", metric)
+	case "diff":
+		var full, ring [][]float64
+		var err error
+		xs, ys, full, err = sweep.Matrix(grid, results, "full", x, y, nil, defaults)
+		if err != nil {
+			return err
+		}
+		_, _, ring, err = sweep.Matrix(grid, results, "ring", x, y, nil, defaults)
+		if err != nil {
+			return err
+		}
+		values = full
+		for row := range values {
+			for col := range values[row] {
+				values[row][col] -= ring[row][col]
+			}
+		}
+		title = messages.T("heatmap.diff")
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	heatmap := plot.Heatmap{
+		Title:  title,
+		XLabel: x,
+		YLabel: y,
+		X:      xs,
+		Y:      ys,
+		Values: values,
+	}
+	return heatmap.WritePNG(file, 800, 700)
+}
+
+// printBoundary печатает границу между режимами без заражения и с заражением для одной топологии.
+// kind - lambda или p, другие значения отсекаются при разборе флагов
+func printBoundary(kind, topology string, system *contagion.BankSystem, trigger string, steps int, precision float64) {
+	var points []sweep.BoundaryPoint
+	switch kind {
+	case "lambda":
+		points = sweep.LambdaBoundary(system, trigger, steps, precision)
+	default:
+		points = sweep.PBoundary(system, trigger, steps, precision)
+	}
+
+	for _, point := range points {
+		if point.Found {
+			fmt.Printf("topology: %s, p: %f, lambda: %f\n", topology, point.P, point.Lambda)
+		} else {
+			fmt.Printf("topology: %s, p: %f, lambda: %f, no transition\n", topology, point.P, point.Lambda)
+		}
+	}
+}
+
+// topologyNames возвращает имена топологий в алфавитном порядке
+func topologyNames(topologies map[string]*contagion.BankSystem) []string {
+	names := make([]string, 0, len(topologies))
+	for name := range topologies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,77 @@
+//go:build !headless
+
+package main
+
+import (
+	"image"
+	"os"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+
+	"github.com/nlypage/BankSystemVisualize/render"
+)
+
+// windowSupported сообщает, что программа собрана с окном визуализатора
+const windowSupported = true
+
+// Game представляет основной объект для визуализации
+type Game struct {
+	scene    *render.Scene
+	nextStep chan struct{}
+	frame    *image.RGBA
+}
+
+// Update это функция, которая обрабатывает обновления экрана
+func (g *Game) Update() error {
+	// Обновляем анимации транзакций
+	g.scene.Advance(1 / float64(ebiten.TPS()))
+
+	// Переход к следующему шагу визуализации
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+		select {
+		case g.nextStep <- struct{}{}:
+		default:
+		}
+	}
+
+	// Принудительный выход из программы
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		os.Exit(0)
+	}
+	return nil
+}
+
+// runStressTest запускает стресс-тест с пошаговым отображением, каждый шаг ждет нажатия Enter
+func (g *Game) runStressTest(failed []string, losses map[string]float64) {
+	render.Play(g.scene, failed, losses, func() { <-g.nextStep })
+}
+
+// Draw это основная функция отрисовки: сцена рисуется в памяти и копируется на экран
+func (g *Game) Draw(screen *ebiten.Image) {
+	if g.frame == nil {
+		g.frame = image.NewRGBA(image.Rect(0, 0, screenWidth, screenHeight))
+	}
+	g.scene.Draw(g.frame)
+	screen.WritePixels(g.frame.Pix)
+}
+
+// Layout возвращает размеры экрана (является заглушкой для имплементации интерфейса ebiten.Game)
+func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
+	return screenWidth, screenHeight
+}
+
+// runWindow открывает окно и проигрывает в нем стресс-тест, после последнего шага вызывается finish
+func runWindow(scene *render.Scene, title string, failed []string, losses map[string]float64, finish func()) error {
+	game := &Game{scene: scene, nextStep: make(chan struct{}, 1)}
+
+	ebiten.SetWindowSize(screenWidth, screenHeight)
+	ebiten.SetWindowTitle(title)
+
+	go func() {
+		game.runStressTest(failed, losses)
+		finish()
+	}()
+
+	return ebiten.RunGame(game)
+}
@@ -0,0 +1,83 @@
+package contagion
+
+// DefaultKind причина банкротства банка
+type DefaultKind int
+
+const (
+	NoDefault        DefaultKind = iota // Банк не обанкротился
+	InitialDefault                      // Банк объявлен банкротом в начале стресс-теста
+	SolvencyDefault                     // Собственный капитал ушел ниже нуля (или потери превысили капитальный буфер)
+	LiquidityDefault                    // Набег вкладчиков исчерпал денежные средства банка
+)
+
+// String возвращает имя причины банкротства
+func (k DefaultKind) String() string {
+	switch k {
+	case NoDefault:
+		return "none"
+	case InitialDefault:
+		return "initial"
+	case SolvencyDefault:
+		return "solvency"
+	case LiquidityDefault:
+		return "liquidity"
+	default:
+		return "unknown"
+	}
+}
+
+// BalanceSheet структурированный баланс банка
+type BalanceSheet struct {
+	InterbankAssets      float64 // Требования к другим банкам (сумма Dependencies) за вычетом потерь по ним
+	InterbankLiabilities float64 // Обязательства перед другими банками
+	ExternalAssets       float64 // Внешние активы, включая рыночную стоимость портфеля
+	Cash                 float64 // Денежные средства
+	Deposits             float64 // Вклады клиентов
+	Equity               float64 // Собственный капитал: активы минус обязательства
+}
+
+// Assets возвращает сумму активов баланса
+func (b BalanceSheet) Assets() float64 {
+	return b.InterbankAssets + b.ExternalAssets + b.Cash
+}
+
+// Liabilities возвращает сумму обязательств баланса
+func (b BalanceSheet) Liabilities() float64 {
+	return b.InterbankLiabilities + b.Deposits
+}
+
+// BalanceSheet возвращает текущий баланс банка. Межбанковские статьи выводятся из Dependencies.
+// Если внешние активы банка не заданы, они выводятся так, что капитал совпадает с Balance
+func (s *BankSystem) BalanceSheet(name string) BalanceSheet {
+	bank := s.Banks[name]
+	sheet := BalanceSheet{
+		InterbankAssets:      s.totalExposure(name) - bank.InterbankLoss,
+		InterbankLiabilities: s.interbankLiabilities(name),
+		ExternalAssets:       s.externalAssets(name) + s.HoldingsValue(name),
+		Cash:                 bank.Cash,
+		Deposits:             bank.Deposits,
+	}
+	sheet.Equity = sheet.Assets() - sheet.Liabilities()
+	return sheet
+}
+
+// defaultKind проверяет, должен ли банк быть признан банкротом, и возвращает причину.
+// Неплатежеспособность проверяется раньше нехватки ликвидности
+func (s *BankSystem) defaultKind(name string) DefaultKind {
+	if s.insolvent(name) {
+		return SolvencyDefault
+	}
+	if s.EnableLiquidity && s.Banks[name].Cash < -balanceTolerance {
+		return LiquidityDefault
+	}
+	return NoDefault
+}
+
+// markDefault помечает банк банкротом с указанной причиной и сообщает об этом наблюдателям
+func (s *BankSystem) markDefault(name string, kind DefaultKind, level int) {
+	bank := s.Banks[name]
+	bank.Bankrupt = true
+	bank.DefaultKind = kind
+	s.Banks[name] = bank
+	s.emit(Event{Type: EventDefault, Level: level, Bank: name, DefaultKind: kind})
+}
@@ -0,0 +1,100 @@
+package contagion
+
+import (
+	"math"
+	"testing"
+)
+
+// sheetSystem возвращает систему с денежными средствами, вкладами клиентов и портфелем общего актива
+func sheetSystem(liquidity bool) *BankSystem {
+	return &BankSystem{
+		LambdaC:         0.1,
+		LambdaF:         0.1,
+		EnablePanic:     true,
+		PanicRate:       0.5,
+		EnableLiquidity: liquidity,
+		Market:          &Market{Prices: map[string]float64{"bond": 10}, Impact: LinearImpact(0.5)},
+		Banks: map[string]Bank{
+			"A": {Balance: 100, Cash: 50, Deposits: 200, Holdings: map[string]float64{"bond": 10}, Dependencies: map[string]float64{"B": 100}},
+			"B": {Balance: 100, Cash: 30, Deposits: 100, Dependencies: map[string]float64{"C": 80, "A": 50}},
+			"C": {Balance: 60, Cash: 20, Holdings: map[string]float64{"bond": 5}, Dependencies: map[string]float64{"A": 60}},
+			"D": {Balance: 500, Cash: 100, Dependencies: map[string]float64{"B": 100}},
+		},
+	}
+}
+
+// checkEquity проверяет, что у работающих банков активы минус обязательства равны Balance
+func checkEquity(t *testing.T, s *BankSystem, when string) {
+	t.Helper()
+	for name, bank := range s.Banks {
+		if bank.Bankrupt {
+			continue
+		}
+		sheet := s.BalanceSheet(name)
+		if math.Abs(sheet.Assets()-sheet.Liabilities()-sheet.Equity) > 1e-9 {
+			t.Errorf("%s: %s: assets %g - liabilities %g != equity %g", when, name, sheet.Assets(), sheet.Liabilities(), sheet.Equity)
+		}
+		if math.Abs(sheet.Equity-bank.Balance) > 1e-9 {
+			t.Errorf("%s: %s: equity %g, balance %g", when, name, sheet.Equity, bank.Balance)
+		}
+	}
+}
+
+func TestBalanceSheetIdentity(t *testing.T) {
+	tests := []struct {
+		name      string
+		liquidity bool
+		explicit  bool // Внешние активы заданы явно, а не выводятся из Balance
+	}{
+		{"derived", false, false},
+		{"derived with liquidity", true, false},
+		{"explicit", false, true},
+		{"explicit with liquidity", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			system := sheetSystem(tt.liquidity)
+			if tt.explicit {
+				for name, bank := range system.Banks {
+					bank.ExternalAssets = system.externalAssets(name)
+					system.Banks[name] = bank
+				}
+			}
+			checkEquity(t, system, "before the shock")
+
+			system.Subscribe(ObserverFunc(func(e Event) {
+				checkEquity(t, system, e.Type.String())
+			}))
+			system.StressTest("A")
+			checkEquity(t, system, "after the cascade")
+		})
+	}
+}
+
+func TestRunOnlyFailureIsLiquidityDefault(t *testing.T) {
+	for _, update := range []UpdateMode{UpdateSequential, UpdateSynchronous} {
+		system := &BankSystem{
+			EnablePanic:     true,
+			PanicRate:       0.5,
+			EnableLiquidity: true,
+			Update:          update,
+			Banks: map[string]Bank{
+				"A": {Balance: 10, Dependencies: map[string]float64{"X": 10}},
+				"X": {Balance: 1000, Cash: 50},
+				"Y": {Balance: 1000, Cash: 0, Dependencies: map[string]float64{"X": 200}},
+			},
+		}
+		system.StressTest("A")
+
+		x, y := system.Banks["X"], system.Banks["Y"]
+		if !x.Bankrupt || x.DefaultKind != LiquidityDefault {
+			t.Errorf("%s: X bankrupt = %t, kind = %s, want liquidity default", update, x.Bankrupt, x.DefaultKind)
+		}
+		if x.Balance != 1000 || y.Balance != 1000 {
+			t.Errorf("%s: run changed equity: X = %g, Y = %g", update, x.Balance, y.Balance)
+		}
+		if x.Cash != -50 || y.Cash != 100 || y.Dependencies["X"] != 100 {
+			t.Errorf("%s: X cash = %g, Y cash = %g, Y claim = %g, want -50, 100, 100", update, x.Cash, y.Cash, y.Dependencies["X"])
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package contagion
+
+import "sort"
+
+// Bankruptcy основная функция для просчитывания последствий банкротства банков
+func (s *BankSystem) Bankruptcy(bankruptBankName string) {
+	s.cascade([]string{bankruptBankName})
+}
+
+// cascade просчитывает каскад банкротств, начиная с банков initial
+func (s *BankSystem) cascade(initial []string) {
+	// Очередь для обработки банкротств текущего уровня
+	currentLevel := initial
+	level := 0
+	s.resetOrder()
+	s.resetPrices()
+	s.captureBuffers()
+	for _, bankName := range initial {
+		kind := s.Banks[bankName].DefaultKind
+		if kind == NoDefault {
+			kind = InitialDefault
+		}
+		s.markDefault(bankName, kind, level)
+	}
+
+	for len(currentLevel) > 0 {
+		s.failed = nil
+
+		// Обрабатываем все банкротства текущего уровня
+		for _, bankName := range currentLevel {
+			bankruptBank := s.Banks[bankName]
+
+			// Запускаем панику для текущего банка
+			s.bankRun(bankName, level)
+
+			// Обрабатываем шок фондирования
+			for _, partnerName := range s.dependencyNames(bankruptBank.Dependencies) {
+				amount := bankruptBank.Dependencies[partnerName]
+				partner := s.Banks[partnerName]
+				if !partner.Bankrupt {
+					shockImpact := amount * s.FundingLossRate(bankName, partnerName)
+					s.writeDown(partnerName, shockImpact)
+					s.emit(Event{Type: EventFundingShock, Level: level, Bank: partnerName, Counterparty: bankName, Cause: bankName, Amount: shockImpact})
+					s.settle(partnerName, level)
+				}
+			}
+
+			// Обрабатываем кредитный шок
+			creditors := s.bankNames(func(name string) float64 {
+				return s.Banks[name].Dependencies[bankName]
+			})
+			for _, partnerName := range creditors {
+				partner := s.Banks[partnerName]
+				if creditAmount, exists := partner.Dependencies[bankName]; exists && !partner.Bankrupt {
+					shockImpact := creditAmount * s.CreditLossRate(partnerName, bankName)
+					s.impair(partnerName, shockImpact)
+					s.emit(Event{Type: EventCreditShock, Level: level, Bank: partnerName, Counterparty: bankName, Cause: bankName, Amount: shockImpact})
+					s.settle(partnerName, level)
+				}
+			}
+
+			// Обрабатываем вынужденную продажу активов банкрота
+			s.fireSale(bankName, 0, level)
+		}
+
+		// В синхронном режиме применяем все шоки уровня разом
+		s.flush()
+
+		// Проверяем новые банкротства для следующего уровня. В режиме UpdateImmediate
+		// часть из них уже признана по ходу уровня
+		level++
+		nextLevel := s.failed
+		for _, bankName := range s.bankNames(s.totalExposure) {
+			if s.Banks[bankName].Bankrupt {
+				continue
+			}
+			if kind := s.defaultKind(bankName); kind != NoDefault {
+				s.markDefault(bankName, kind, level)
+				nextLevel = append(nextLevel, bankName)
+			}
+		}
+
+		// Переходим к следующему уровню
+		currentLevel = nextLevel
+	}
+}
+
+// BankRun симулирует набег вкладчиков на партнеров обанкротившегося банка
+func (s *BankSystem) BankRun(bankruptBankName string) {
+	s.bankRun(bankruptBankName, 0)
+	s.flush()
+}
+
+// bankRun реализация BankRun с указанием уровня каскада для событий
+func (s *BankSystem) bankRun(bankruptBankName string, level int) {
+	if !s.EnablePanic {
+		return
+	}
+
+	bankruptBank := s.Banks[bankruptBankName]
+
+	// Находим всех партнеров обанкротившегося банка
+	partners := make(map[string]bool)
+
+	// Кредиторы (те, кто вложил в банкрота)
+	for bankName, bank := range s.Banks {
+		if _, exists := bank.Dependencies[bankruptBankName]; exists {
+			partners[bankName] = true
+		}
+	}
+
+	// Должники (те, кому банкрот дал в долг)
+	for debtor := range bankruptBank.Dependencies {
+		partners[debtor] = true
+	}
+
+	// Симулируем набег на каждого партнера
+	partnerNames := make([]string, 0, len(partners))
+	for partnerName := range partners {
+		partnerNames = append(partnerNames, partnerName)
+	}
+	for _, partnerName := range s.ordered(partnerNames, s.totalExposure) {
+		partner := s.Banks[partnerName]
+		if !partner.Bankrupt { // Проверяем, что партнер еще не обанкротился
+
+			// Закрываем долю p вкладов
+			withdrawn := 0.0
+			depositors := s.bankNames(func(name string) float64 {
+				return s.Banks[name].Dependencies[partnerName]
+			})
+			for _, bankName := range depositors {
+				bank := s.Banks[bankName]
+				if !bank.Bankrupt { // Проверяем что банк еще не обанкротился
+					if amount, exists := bank.Dependencies[partnerName]; exists {
+						if s.EnableLiquidity {
+							s.withdraw(bankName, partnerName, amount*s.PanicRate)
+						} else {
+							// Исходная модель: вклад переходит из капитала партнера в капитал вкладчика
+							s.adjust(partnerName, -amount*s.PanicRate)
+							s.adjust(bankName, amount*s.PanicRate)
+							s.adjustCash(partnerName, -amount*s.PanicRate)
+							s.adjustCash(bankName, amount*s.PanicRate)
+						}
+						withdrawn += amount * s.PanicRate
+
+						s.emit(Event{Type: EventBankRun, Level: level, Bank: partnerName, Counterparty: bankName, Cause: bankruptBankName, Amount: amount * s.PanicRate})
+					}
+				}
+			}
+
+			// Партнер продает активы, чтобы расплатиться с вкладчиками, и только после этого
+			// проверяется, хватило ли ему денежных средств
+			if withdrawn > 0 {
+				s.fireSale(partnerName, withdrawn, level)
+			}
+			s.settle(partnerName, level)
+		}
+	}
+}
+
+// StressTest функция для запуска стресс-теста, возвращает количество обанкротившихся банков
+func (s *BankSystem) StressTest(bankName string) int {
+	return s.Shock([]string{bankName}, nil)
+}
+
+// Shock применяет начальный шок и запускает выбранную модель: банки failed объявляются банкротами,
+// остальные теряют losses[name] из баланса. Возвращает количество обанкротившихся банков
+func (s *BankSystem) Shock(failed []string, losses map[string]float64) int {
+	switch s.Model {
+	case ModelEisenbergNoe:
+		s.eisenbergNoe(failed, losses)
+	case ModelDebtRank:
+		shocks := make(map[string]float64, len(failed)+len(losses))
+		for name, loss := range losses {
+			if balance := s.Banks[name].Balance; balance > 0 {
+				shocks[name] = loss / balance
+			} else {
+				shocks[name] = 1
+			}
+		}
+		for _, name := range failed {
+			shocks[name] = 1
+		}
+		s.DebtRank(shocks)
+	default:
+		initial := make([]string, 0, len(failed))
+		isFailed := make(map[string]bool, len(failed))
+		for _, name := range failed {
+			bank := s.Banks[name]
+			bank.Bankrupt = true
+			bank.DefaultKind = InitialDefault
+			bank.Balance = -1
+			s.Banks[name] = bank
+			isFailed[name] = true
+			initial = append(initial, name)
+		}
+
+		// Частичные потери могут сами по себе сделать банк неплатежеспособным
+		for _, name := range s.ordered(mapKeys(losses), func(name string) float64 { return losses[name] }) {
+			if isFailed[name] {
+				continue
+			}
+			bank := s.Banks[name]
+			bank.Balance -= losses[name]
+			bank.ExternalLoss += losses[name]
+			s.Banks[name] = bank
+			s.emit(Event{Type: EventInitialLoss, Level: 0, Bank: name, Amount: losses[name]})
+
+			if bank.Balance < -balanceTolerance && !bank.Bankrupt {
+				bank.DefaultKind = SolvencyDefault
+				s.Banks[name] = bank
+				initial = append(initial, name)
+			}
+		}
+
+		if len(initial) > 0 {
+			s.cascade(initial)
+		}
+	}
+	return s.DefaultCount()
+}
+
+// DefaultCount возвращает количество обанкротившихся банков
+func (s *BankSystem) DefaultCount() int {
+	bankruptedCount := 0
+	for _, b := range s.Banks {
+		if b.Bankrupt {
+			bankruptedCount++
+		}
+	}
+	return bankruptedCount
+}
+
+// TotalLoss возвращает суммарное снижение балансов банков относительно исходного состояния initial.
+// Банки складываются по порядку имен, чтобы сумма не зависела от порядка обхода словаря
+func (s *BankSystem) TotalLoss(initial map[string]Bank) float64 {
+	names := make([]string, 0, len(initial))
+	for name := range initial {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	total := 0.0
+	for _, name := range names {
+		total += initial[name].Balance - s.Banks[name].Balance
+	}
+	return total
+}
@@ -0,0 +1,197 @@
+package contagion
+
+import "math"
+
+// DistressState состояние узла в алгоритме DebtRank
+type DistressState int
+
+const (
+	Undistressed DistressState = iota // Банк не затронут
+	Distressed                        // Банк получил дистресс и на следующем шаге передаст его дальше
+	Inactive                          // Банк уже передал дистресс и больше не распространяет его
+)
+
+// String возвращает имя состояния
+func (d DistressState) String() string {
+	switch d {
+	case Undistressed:
+		return "undistressed"
+	case Distressed:
+		return "distressed"
+	case Inactive:
+		return "inactive"
+	default:
+		return "unknown"
+	}
+}
+
+// DebtRankResult результат расчета DebtRank
+type DebtRankResult struct {
+	Rank     float64                  // Групповой DebtRank: доля экономической ценности, потерянная из-за распространения
+	Distress map[string]float64       // Итоговый уровень дистресса h каждого банка (1 - дефолт)
+	States   map[string]DistressState // Итоговые состояния банков
+	Rounds   int                      // Количество шагов распространения
+}
+
+// economicValues возвращает относительную экономическую ценность банков, пропорциональную Balance
+func (s *BankSystem) economicValues() map[string]float64 {
+	total := 0.0
+	for _, bank := range s.Banks {
+		total += math.Max(0, bank.Balance)
+	}
+
+	values := make(map[string]float64, len(s.Banks))
+	for name, bank := range s.Banks {
+		if total > 0 {
+			values[name] = math.Max(0, bank.Balance) / total
+		}
+	}
+	return values
+}
+
+// impact возвращает долю капитала кредитора, которую он теряет при дефолте должника.
+// Капитал берется из equity - балансов до начала распространения дистресса
+func (s *BankSystem) impact(debtor, creditor string, equity map[string]float64) float64 {
+	exposure := s.Banks[creditor].Dependencies[debtor]
+	if exposure <= 0 {
+		return 0
+	}
+	if equity[creditor] <= 0 {
+		return 1
+	}
+	return math.Min(1, exposure/equity[creditor])
+}
+
+// DebtRank рассчитывает групповой DebtRank для начальных шоков shocks (доля потерянного капитала от 0 до 1).
+// Уровень дистресса каждого банка сохраняется в Bank.Distress, баланс уменьшается на ту же долю
+// исходного капитала, банки с дистрессом 1 помечаются банкротами. Результат также сохраняется в DebtRankResult
+func (s *BankSystem) DebtRank(shocks map[string]float64) DebtRankResult {
+	s.resetOrder()
+	names := s.bankNames(s.totalExposure)
+	values := s.economicValues()
+
+	equity := make(map[string]float64, len(names))
+	for _, name := range names {
+		equity[name] = s.Banks[name].Balance
+	}
+
+	distress := make(map[string]float64, len(names))
+	states := make(map[string]DistressState, len(names))
+	for _, name := range names {
+		states[name] = Undistressed
+	}
+
+	initial := 0.0
+	for _, name := range s.ordered(mapKeys(shocks), func(name string) float64 { return shocks[name] }) {
+		h := math.Max(0, math.Min(1, shocks[name]))
+		if h == 0 {
+			continue
+		}
+		distress[name] = h
+		states[name] = Distressed
+		initial += h * values[name]
+		s.setDistress(name, h, equity[name])
+		s.emit(Event{Type: EventDistress, Level: 0, Bank: name, Amount: h})
+	}
+	s.applyDistress(names, distress, 0)
+
+	round := 0
+	for {
+		active := make([]string, 0)
+		for _, name := range names {
+			if states[name] == Distressed {
+				active = append(active, name)
+			}
+		}
+		if len(active) == 0 {
+			break
+		}
+		round++
+
+		// Дистресс распространяется от банков, которые получили его на прошлом шаге.
+		// В событие попадает прирост после ограничения дистресса единицей
+		next := make(map[string]float64, len(names))
+		for name, h := range distress {
+			next[name] = h
+		}
+		for _, debtor := range active {
+			for _, creditor := range names {
+				increment := s.impact(debtor, creditor, equity) * distress[debtor]
+				if increment <= 0 {
+					continue
+				}
+				previous := next[creditor]
+				next[creditor] = math.Min(1, previous+increment)
+				if applied := next[creditor] - previous; applied > 0 {
+					s.setDistress(creditor, next[creditor], equity[creditor])
+					s.emit(Event{Type: EventDistress, Level: round, Bank: creditor, Counterparty: debtor, Cause: debtor, Amount: applied})
+				}
+			}
+		}
+
+		for _, name := range names {
+			switch {
+			case states[name] == Distressed:
+				states[name] = Inactive
+			case states[name] == Undistressed && next[name] > 0:
+				states[name] = Distressed
+			}
+		}
+		distress = next
+		s.applyDistress(names, distress, round)
+	}
+
+	total := 0.0
+	for name, h := range distress {
+		total += h * values[name]
+	}
+	result := DebtRankResult{
+		Rank:     total - initial,
+		Distress: distress,
+		States:   states,
+		Rounds:   round,
+	}
+	s.DebtRankResult = &result
+	return result
+}
+
+// setDistress записывает уровень дистресса банка и списывает из баланса ту же долю исходного капитала initial
+func (s *BankSystem) setDistress(name string, h, initial float64) {
+	bank := s.Banks[name]
+	bank.Distress = h
+	bank.Balance = initial - h*math.Max(0, initial)
+	s.Banks[name] = bank
+}
+
+// DebtRanks возвращает DebtRank каждого банка, когда он единственный получает полный начальный шок.
+// Каждый расчет проводится на копии банков, исходная система не меняется
+func (s *BankSystem) DebtRanks() map[string]float64 {
+	ranks := make(map[string]float64, len(s.Banks))
+	for name := range s.Banks {
+		system := &BankSystem{Banks: CloneBanks(s.Banks), Order: s.Order}
+		ranks[name] = system.DebtRank(map[string]float64{name: 1}).Rank
+	}
+	return ranks
+}
+
+// applyDistress помечает банкротами банки, дистресс которых достиг единицы
+func (s *BankSystem) applyDistress(names []string, distress map[string]float64, level int) {
+	for _, name := range names {
+		if distress[name] >= 1 && !s.Banks[name].Bankrupt {
+			if level == 0 {
+				s.markDefault(name, InitialDefault, level)
+			} else {
+				s.markDefault(name, SolvencyDefault, level)
+			}
+		}
+	}
+}
+
+// mapKeys возвращает ключи словаря
+func mapKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,128 @@
+package contagion
+
+import (
+	"math"
+	"testing"
+)
+
+// debtRankNetwork возвращает пример из трех банков с капиталом 10, 20 и 30.
+// B вложил 10 в A (W_AB = 0,5), C вложил 15 в B (W_BC = 0,5) и exposureCA в A
+func debtRankNetwork(exposureCA float64) *BankSystem {
+	return &BankSystem{
+		Model: ModelDebtRank,
+		Banks: map[string]Bank{
+			"A": {Balance: 10},
+			"B": {Balance: 20, Dependencies: map[string]float64{"A": 10}},
+			"C": {Balance: 30, Dependencies: map[string]float64{"A": exposureCA, "B": 15}},
+		},
+	}
+}
+
+func TestDebtRank(t *testing.T) {
+	tests := []struct {
+		name       string
+		exposureCA float64
+		rank       float64
+		distress   map[string]float64
+		increments []float64 // Приросты дистресса в событиях после начального шока
+		bankrupt   map[string]bool
+	}{
+		{
+			// Шаг 1: h_B = 0,5, h_C = 0,2. Шаг 2: h_C += 0,5·0,5.
+			// R = 0,5·20/60 + 0,45·30/60 = 0,391667
+			name:       "partial distress",
+			exposureCA: 6,
+			rank:       0.5/3 + 0.45/2,
+			distress:   map[string]float64{"A": 1, "B": 0.5, "C": 0.45},
+			increments: []float64{0.5, 0.2, 0.25},
+			bankrupt:   map[string]bool{"A": true},
+		},
+		{
+			// Шаг 1: h_C = 0,8. Шаг 2: прирост 0,25 ограничивается единицей и дает 0,2
+			name:       "capped distress",
+			exposureCA: 24,
+			rank:       0.5/3 + 1.0/2,
+			distress:   map[string]float64{"A": 1, "B": 0.5, "C": 1},
+			increments: []float64{0.5, 0.8, 0.2},
+			bankrupt:   map[string]bool{"A": true, "C": true},
+		},
+	}
+
+	initial := map[string]float64{"A": 10, "B": 20, "C": 30}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			system := debtRankNetwork(tt.exposureCA)
+			recorder := &Recorder{}
+			system.Subscribe(recorder)
+			defaults := system.StressTest("A")
+
+			result := system.DebtRankResult
+			if result == nil {
+				t.Fatal("DebtRank result is not attached to the system")
+			}
+			if math.Abs(result.Rank-tt.rank) > 1e-9 {
+				t.Errorf("rank = %g, want %g", result.Rank, tt.rank)
+			}
+			if result.Rounds != 2 {
+				t.Errorf("rounds = %d, want 2", result.Rounds)
+			}
+			for name, h := range tt.distress {
+				bank := system.Banks[name]
+				if math.Abs(bank.Distress-h) > 1e-9 || math.Abs(result.Distress[name]-h) > 1e-9 {
+					t.Errorf("distress of %s = %g, want %g", name, bank.Distress, h)
+				}
+				if want := initial[name] * (1 - h); math.Abs(bank.Balance-want) > 1e-9 {
+					t.Errorf("balance of %s = %g, want %g", name, bank.Balance, want)
+				}
+				if bank.Bankrupt != tt.bankrupt[name] {
+					t.Errorf("bankrupt %s = %t, want %t", name, bank.Bankrupt, tt.bankrupt[name])
+				}
+				if result.States[name] != Inactive {
+					t.Errorf("state of %s = %s, want inactive", name, result.States[name])
+				}
+			}
+			if defaults != len(tt.bankrupt) {
+				t.Errorf("defaults = %d, want %d", defaults, len(tt.bankrupt))
+			}
+
+			var increments []float64
+			for _, e := range recorder.Events {
+				if e.Type == EventDistress && e.Cause != "" {
+					increments = append(increments, e.Amount)
+				}
+			}
+			if len(increments) != len(tt.increments) {
+				t.Fatalf("increments = %v, want %v", increments, tt.increments)
+			}
+			for i := range increments {
+				if math.Abs(increments[i]-tt.increments[i]) > 1e-9 {
+					t.Errorf("increment %d = %g, want %g", i, increments[i], tt.increments[i])
+				}
+			}
+
+			// Потери равны дистрессу, умноженному на исходный капитал
+			wantLoss := 0.0
+			for name, h := range tt.distress {
+				wantLoss += h * initial[name]
+			}
+			if loss := system.TotalLoss(debtRankNetwork(tt.exposureCA).Banks); math.Abs(loss-wantLoss) > 1e-9 {
+				t.Errorf("total loss = %g, want %g", loss, wantLoss)
+			}
+		})
+	}
+}
+
+func TestDebtRanks(t *testing.T) {
+	system := debtRankNetwork(6)
+	ranks := system.DebtRanks()
+
+	want := map[string]float64{"A": 0.5/3 + 0.45/2, "B": 0.5 * 0.5, "C": 0}
+	for name, rank := range want {
+		if math.Abs(ranks[name]-rank) > 1e-9 {
+			t.Errorf("DebtRank of %s = %g, want %g", name, ranks[name], rank)
+		}
+	}
+	if system.Banks["B"].Distress != 0 || system.Banks["B"].Balance != 20 {
+		t.Errorf("DebtRanks changed the original system: %+v", system.Banks["B"])
+	}
+}
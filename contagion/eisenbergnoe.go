@@ -0,0 +1,202 @@
+package contagion
+
+import "math"
+
+// clearingTolerance точность расчета клирингового вектора
+const clearingTolerance = 1e-9
+
+// clearingMaxIterations ограничение числа итераций при поиске неподвижной точки
+const clearingMaxIterations = 100000
+
+// ClearingResult результат расчета клирингового платежного вектора Айзенберга–Ноэ
+type ClearingResult struct {
+	Payments     map[string]float64 // Клиринговые платежи p_i каждого банка
+	Liabilities  map[string]float64 // Номинальные межбанковские обязательства каждого банка
+	Defaulted    []string           // Банки, не исполнившие обязательства, в порядке дефолта
+	DefaultRound map[string]int     // Итерация фиктивного дефолта, на которой банк признан банкротом
+
+	// Recovery[creditor][debtor] сумма, которую кредитор получил от должника
+	Recovery map[string]map[string]float64
+	// Rounds количество итераций алгоритма фиктивного дефолта
+	Rounds int
+}
+
+// RecoveryRate возвращает долю номинальных обязательств должника, которую получают его кредиторы
+func (r ClearingResult) RecoveryRate(debtor string) float64 {
+	if r.Liabilities[debtor] == 0 {
+		return 1
+	}
+	return r.Payments[debtor] / r.Liabilities[debtor]
+}
+
+// interbankLiabilities возвращает сумму, которую банк должен другим банкам
+func (s *BankSystem) interbankLiabilities(name string) float64 {
+	total := 0.0
+	for _, bank := range s.Banks {
+		total += bank.Dependencies[name]
+	}
+	return total
+}
+
+// externalAssets возвращает внешние активы банка без портфеля общих активов за вычетом потерь на них.
+// Если они не заданы, то выводятся из Balance так, чтобы активы баланса минус обязательства
+// совпадали с Balance
+func (s *BankSystem) externalAssets(name string) float64 {
+	bank := s.Banks[name]
+	if bank.ExternalAssets != 0 {
+		return bank.ExternalAssets - bank.ExternalLoss
+	}
+	interbank := s.totalExposure(name) - bank.InterbankLoss
+	return math.Max(0, bank.Balance+s.interbankLiabilities(name)+bank.Deposits-interbank-bank.Cash-s.HoldingsValue(name))
+}
+
+// outsideAssets возвращает активы банка вне межбанковской сети за вычетом вкладов клиентов,
+// которые выплачиваются раньше межбанковских обязательств
+func (s *BankSystem) outsideAssets(name string) float64 {
+	bank := s.Banks[name]
+	return s.externalAssets(name) + s.HoldingsValue(name) + bank.Cash - bank.Deposits
+}
+
+// EisenbergNoe рассчитывает клиринговый платежный вектор алгоритмом фиктивного дефолта.
+// Банки failed теряют все внешние активы и считаются банкротами с первой итерации.
+// Каждая итерация алгоритма выдается наблюдателям как уровень каскада, а выплаты банкротов
+// кредиторам - событиями EventRecovery после последней итерации. Результат также сохраняется в Clearing
+func (s *BankSystem) EisenbergNoe(failed ...string) ClearingResult {
+	return s.eisenbergNoe(failed, nil)
+}
+
+// eisenbergNoe реализация EisenbergNoe, в которой банки дополнительно теряют losses[name] внешних активов
+func (s *BankSystem) eisenbergNoe(failed []string, losses map[string]float64) ClearingResult {
+	s.resetOrder()
+	names := s.bankNames(s.totalExposure)
+
+	// Номинальные обязательства и внешние активы
+	liabilities := make(map[string]float64, len(names))
+	external := make(map[string]float64, len(names))
+	for _, name := range names {
+		liabilities[name] = s.interbankLiabilities(name)
+		external[name] = math.Max(0, s.outsideAssets(name)-losses[name])
+	}
+
+	result := ClearingResult{
+		Payments:     make(map[string]float64, len(names)),
+		Liabilities:  liabilities,
+		DefaultRound: make(map[string]int),
+		Recovery:     make(map[string]map[string]float64, len(names)),
+	}
+
+	for name, amount := range liabilities {
+		result.Payments[name] = amount
+	}
+
+	// inflow возвращает сумму, которую банк получает от должников при текущих платежах
+	inflow := func(name string) float64 {
+		total := 0.0
+		for debtor, amount := range s.Banks[name].Dependencies {
+			if liabilities[debtor] > 0 {
+				total += amount / liabilities[debtor] * result.Payments[debtor]
+			}
+		}
+		return total
+	}
+
+	// Принудительные банкротства на нулевой итерации
+	defaulted := make(map[string]bool)
+	newDefaults := make([]string, 0)
+	for _, name := range failed {
+		external[name] = 0
+		if !defaulted[name] {
+			defaulted[name] = true
+			newDefaults = append(newDefaults, name)
+		}
+	}
+
+	round := 0
+	for len(newDefaults) > 0 || round == 0 {
+		for _, name := range newDefaults {
+			result.Defaulted = append(result.Defaulted, name)
+			result.DefaultRound[name] = round
+
+			if round == 0 {
+				s.markDefault(name, InitialDefault, round)
+			} else {
+				s.markDefault(name, SolvencyDefault, round)
+			}
+		}
+
+		// Ищем платежи банкротов при фиксированном множестве банкротов,
+		// итерации сходятся сверху к наибольшей неподвижной точке
+		previous := make(map[string]float64, len(names))
+		for name, amount := range result.Payments {
+			previous[name] = amount
+		}
+		for i := 0; i < clearingMaxIterations; i++ {
+			change := 0.0
+			for _, name := range names {
+				if !defaulted[name] {
+					continue
+				}
+				payment := math.Min(liabilities[name], math.Max(0, external[name]+inflow(name)))
+				change = math.Max(change, math.Abs(payment-result.Payments[name]))
+				result.Payments[name] = payment
+			}
+			if change < clearingTolerance {
+				break
+			}
+		}
+
+		// Собственный капитал после клиринга
+		for _, name := range names {
+			bank := s.Banks[name]
+			bank.Balance = external[name] + inflow(name) - liabilities[name]
+			s.Banks[name] = bank
+		}
+
+		// Кредиторы теряют разницу между прошлыми и новыми платежами, события рассылаются
+		// после пересчета капитала, чтобы в них попали балансы после клиринга
+		for _, debtor := range names {
+			paid := previous[debtor] - result.Payments[debtor]
+			if paid <= clearingTolerance || liabilities[debtor] == 0 {
+				continue
+			}
+			for _, creditor := range s.bankNames(func(name string) float64 { return s.Banks[name].Dependencies[debtor] }) {
+				if amount, exists := s.Banks[creditor].Dependencies[debtor]; exists {
+					s.emit(Event{Type: EventCreditShock, Level: round, Bank: creditor, Counterparty: debtor, Cause: debtor,
+						Amount: amount / liabilities[debtor] * paid})
+				}
+			}
+		}
+
+		// Новые банкроты: активов не хватает на номинальные обязательства
+		round++
+		newDefaults = newDefaults[:0]
+		for _, name := range names {
+			if !defaulted[name] && external[name]+inflow(name) < liabilities[name]-clearingTolerance {
+				defaulted[name] = true
+				newDefaults = append(newDefaults, name)
+			}
+		}
+	}
+	result.Rounds = round
+
+	for _, creditor := range names {
+		result.Recovery[creditor] = make(map[string]float64)
+		for debtor, amount := range s.Banks[creditor].Dependencies {
+			if liabilities[debtor] > 0 {
+				result.Recovery[creditor][debtor] = amount / liabilities[debtor] * result.Payments[debtor]
+			}
+		}
+	}
+
+	// Сколько каждый кредитор получил от банкротов
+	for _, debtor := range result.Defaulted {
+		for _, creditor := range s.bankNames(func(name string) float64 { return s.Banks[name].Dependencies[debtor] }) {
+			if recovered, exists := result.Recovery[creditor][debtor]; exists {
+				s.emit(Event{Type: EventRecovery, Level: round, Bank: debtor, Counterparty: creditor, Cause: debtor, Amount: recovered})
+			}
+		}
+	}
+
+	s.Clearing = &result
+	return result
+}
@@ -0,0 +1,133 @@
+package contagion
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// clearingNetwork возвращает сеть из трех банков: A должен B и C по 100, B должен C 50, C должен A 40.
+// Внешние активы A и B задаются, у C они равны 100
+func clearingNetwork(externalA, externalB float64) *BankSystem {
+	return &BankSystem{
+		Model: ModelEisenbergNoe,
+		Banks: map[string]Bank{
+			"A": {ExternalAssets: externalA, Dependencies: map[string]float64{"C": 40}},
+			"B": {ExternalAssets: externalB, Dependencies: map[string]float64{"A": 100}},
+			"C": {ExternalAssets: 100, Dependencies: map[string]float64{"A": 100, "B": 50}},
+		},
+	}
+}
+
+func TestEisenbergNoe(t *testing.T) {
+	tests := []struct {
+		name                 string
+		externalA, externalB float64
+		failed               []string
+		payments             map[string]float64
+		balances             map[string]float64
+		defaulted            []string
+		rounds               map[string]int
+	}{
+		{
+			// Всем хватает активов: платежи равны номинальным обязательствам
+			name:      "no defaults",
+			externalA: 200, externalB: 20,
+			payments:  map[string]float64{"A": 200, "B": 50, "C": 40},
+			balances:  map[string]float64{"A": 40, "B": 70, "C": 210},
+			defaulted: nil,
+			rounds:    map[string]int{},
+		},
+		{
+			// p_A = 60 + 40 = 100, B получает 50 и платит полностью
+			name:      "one default",
+			externalA: 60, externalB: 20,
+			payments:  map[string]float64{"A": 100, "B": 50, "C": 40},
+			balances:  map[string]float64{"A": -100, "B": 20, "C": 160},
+			defaulted: []string{"A"},
+			rounds:    map[string]int{"A": 1},
+		},
+		{
+			// p_A = 20 + 40 = 60, B получает 30 из своих 50, p_B = 30
+			name:      "second round default",
+			externalA: 20, externalB: 0,
+			payments:  map[string]float64{"A": 60, "B": 30, "C": 40},
+			balances:  map[string]float64{"A": -140, "B": -20, "C": 120},
+			defaulted: []string{"A", "B"},
+			rounds:    map[string]int{"A": 1, "B": 2},
+		},
+		{
+			// A теряет внешние активы: p_A = 40, B получает 20 и платит 40
+			name:      "forced default",
+			externalA: 200, externalB: 20,
+			failed:    []string{"A"},
+			payments:  map[string]float64{"A": 40, "B": 40, "C": 40},
+			balances:  map[string]float64{"A": -160, "B": -10, "C": 120},
+			defaulted: []string{"A", "B"},
+			rounds:    map[string]int{"A": 0, "B": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			system := clearingNetwork(tt.externalA, tt.externalB)
+			result := system.EisenbergNoe(tt.failed...)
+
+			for name, want := range tt.payments {
+				if got := result.Payments[name]; math.Abs(got-want) > 1e-6 {
+					t.Errorf("payment of %s = %g, want %g", name, got, want)
+				}
+				if got := system.Banks[name].Balance; math.Abs(got-tt.balances[name]) > 1e-6 {
+					t.Errorf("balance of %s = %g, want %g", name, got, tt.balances[name])
+				}
+			}
+			if !reflect.DeepEqual(result.Defaulted, tt.defaulted) {
+				t.Errorf("defaulted = %v, want %v", result.Defaulted, tt.defaulted)
+			}
+			if !reflect.DeepEqual(result.DefaultRound, tt.rounds) {
+				t.Errorf("default rounds = %v, want %v", result.DefaultRound, tt.rounds)
+			}
+			if system.Clearing == nil || !reflect.DeepEqual(*system.Clearing, result) {
+				t.Errorf("clearing result is not attached to the system")
+			}
+		})
+	}
+}
+
+func TestEisenbergNoeRecovery(t *testing.T) {
+	system := clearingNetwork(60, 20)
+	recorder := &Recorder{}
+	system.Subscribe(recorder)
+	system.Shock(nil, nil)
+
+	result := system.Clearing
+	if result == nil {
+		t.Fatal("Shock did not attach the clearing result")
+	}
+	if rate := result.RecoveryRate("A"); math.Abs(rate-0.5) > 1e-9 {
+		t.Errorf("recovery rate of A = %g, want 0.5", rate)
+	}
+	if rate := result.RecoveryRate("B"); rate != 1 {
+		t.Errorf("recovery rate of B = %g, want 1", rate)
+	}
+
+	// Кредиторы A получают по половине своих требований
+	recoveries := make(map[string]float64)
+	for _, e := range recorder.Events {
+		if e.Type == EventRecovery {
+			if e.Bank != "A" {
+				t.Errorf("recovery from solvent bank %s", e.Bank)
+			}
+			recoveries[e.Counterparty] = e.Amount
+		}
+	}
+	want := map[string]float64{"B": 50, "C": 50}
+	for creditor, amount := range want {
+		if math.Abs(recoveries[creditor]-amount) > 1e-6 || math.Abs(result.Recovery[creditor]["A"]-amount) > 1e-6 {
+			t.Errorf("recovery of %s = %g (event %g), want %g", creditor, result.Recovery[creditor]["A"], recoveries[creditor], amount)
+		}
+	}
+	if len(recoveries) != len(want) {
+		t.Errorf("recoveries = %v, want %v", recoveries, want)
+	}
+}
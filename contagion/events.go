@@ -0,0 +1,99 @@
+package contagion
+
+// EventType тип события каскада
+type EventType int
+
+const (
+	EventDefault      EventType = iota // Банкротство банка
+	EventFundingShock                  // Шок фондирования: банкрот забирает свои вложения у должника
+	EventCreditShock                   // Кредитный шок: кредитор теряет часть вложений в банкрота
+	EventBankRun                       // Набег вкладчиков: банк забирает часть своего вклада у партнера банкрота
+	EventDistress                      // Рост дистресса в модели DebtRank, Amount - прирост уровня дистресса
+	EventFireSale                      // Вынужденная продажа: банк теряет на переоценке активов, проданных Counterparty
+	EventRecovery                      // Итог клиринга Айзенберга–Ноэ: банкрот Bank выплачивает кредитору Counterparty сумму Amount
+	EventInitialLoss                   // Частичные потери банка в начальном шоке
+)
+
+// String возвращает машиночитаемое имя типа события
+func (t EventType) String() string {
+	switch t {
+	case EventDefault:
+		return "default"
+	case EventFundingShock:
+		return "funding_shock"
+	case EventCreditShock:
+		return "credit_shock"
+	case EventBankRun:
+		return "bank_run"
+	case EventDistress:
+		return "distress"
+	case EventFireSale:
+		return "fire_sale"
+	case EventRecovery:
+		return "recovery"
+	case EventInitialLoss:
+		return "initial_loss"
+	default:
+		return "unknown"
+	}
+}
+
+// Event представляет одно событие каскада банкротств
+type Event struct {
+	Type  EventType
+	Level int // Уровень каскада, на котором произошло событие (0 - исходное банкротство)
+
+	Bank         string  // Банк, который обанкротился или потерял средства
+	Counterparty string  // Банк, в сторону которого ушли средства (пустой для банкротства)
+	Cause        string  // Обанкротившийся банк, из-за которого произошло событие
+	Amount       float64 // Размер потерь банка Bank
+
+	DefaultKind DefaultKind // Причина банкротства для EventDefault
+
+	// Балансы Bank и Counterparty после события. В синхронном режиме учитывают
+	// еще не примененные изменения текущего уровня
+	Balance             float64
+	CounterpartyBalance float64
+}
+
+// Observer получает события каскада по мере их возникновения
+type Observer interface {
+	OnEvent(e Event)
+}
+
+// ObserverFunc позволяет использовать обычную функцию в качестве Observer
+type ObserverFunc func(e Event)
+
+// OnEvent вызывает саму функцию
+func (f ObserverFunc) OnEvent(e Event) {
+	f(e)
+}
+
+// Recorder сохраняет все полученные события, удобен для тестов и последующего анализа
+type Recorder struct {
+	Events []Event
+}
+
+// OnEvent добавляет событие в список
+func (r *Recorder) OnEvent(e Event) {
+	r.Events = append(r.Events, e)
+}
+
+// Subscribe подписывает наблюдателя на события системы
+func (s *BankSystem) Subscribe(o Observer) {
+	s.observers = append(s.observers, o)
+}
+
+// emit дополняет событие балансами участников и рассылает его всем подписчикам
+func (s *BankSystem) emit(e Event) {
+	if len(s.observers) == 0 {
+		return
+	}
+	e.Balance = s.Banks[e.Bank].Balance + s.pending[e.Bank].balance
+	if e.Counterparty != "" {
+		e.CounterpartyBalance = s.Banks[e.Counterparty].Balance + s.pending[e.Counterparty].balance
+	}
+	for _, o := range s.observers {
+		o.OnEvent(e)
+	}
+}
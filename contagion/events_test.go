@@ -0,0 +1,81 @@
+package contagion
+
+import "testing"
+
+// chain возвращает цепочку A → B → C: каждый банк вложил 100 в следующего и имеет баланс 10
+func chain() *BankSystem {
+	return &BankSystem{
+		LambdaC: 0.5,
+		LambdaF: 0.5,
+		Banks: map[string]Bank{
+			"A": {Balance: 10, Dependencies: map[string]float64{"B": 100}},
+			"B": {Balance: 10, Dependencies: map[string]float64{"C": 100}},
+			"C": {Balance: 10},
+		},
+	}
+}
+
+func TestCascadeEvents(t *testing.T) {
+	system := chain()
+	recorder := &Recorder{}
+	system.Subscribe(recorder)
+
+	if defaults := system.StressTest("A"); defaults != 3 {
+		t.Fatalf("defaults = %d, want 3", defaults)
+	}
+
+	want := []Event{
+		{Type: EventDefault, Level: 0, Bank: "A", DefaultKind: InitialDefault, Balance: -1},
+		{Type: EventFundingShock, Level: 0, Bank: "B", Counterparty: "A", Cause: "A", Amount: 50, Balance: -40, CounterpartyBalance: -1},
+		{Type: EventDefault, Level: 1, Bank: "B", DefaultKind: SolvencyDefault, Balance: -40},
+		{Type: EventFundingShock, Level: 1, Bank: "C", Counterparty: "B", Cause: "B", Amount: 50, Balance: -40, CounterpartyBalance: -40},
+		{Type: EventDefault, Level: 2, Bank: "C", DefaultKind: SolvencyDefault, Balance: -40},
+	}
+	if len(recorder.Events) != len(want) {
+		t.Fatalf("got %d events, want %d: %+v", len(recorder.Events), len(want), recorder.Events)
+	}
+	for i, e := range recorder.Events {
+		if e != want[i] {
+			t.Errorf("event %d = %+v, want %+v", i, e, want[i])
+		}
+	}
+}
+
+func TestObservers(t *testing.T) {
+	system := chain()
+	recorder := &Recorder{}
+	counts := make(map[EventType]int)
+	system.Subscribe(recorder)
+	system.Subscribe(ObserverFunc(func(e Event) {
+		counts[e.Type]++
+	}))
+	system.StressTest("A")
+
+	if len(recorder.Events) != counts[EventDefault]+counts[EventFundingShock] {
+		t.Errorf("observers got different events: %d vs %v", len(recorder.Events), counts)
+	}
+	if counts[EventDefault] != 3 || counts[EventFundingShock] != 2 {
+		t.Errorf("counts = %v, want 3 defaults and 2 funding shocks", counts)
+	}
+}
+
+func TestEventTypeString(t *testing.T) {
+	tests := []struct {
+		event EventType
+		want  string
+	}{
+		{EventDefault, "default"},
+		{EventFundingShock, "funding_shock"},
+		{EventCreditShock, "credit_shock"},
+		{EventBankRun, "bank_run"},
+		{EventDistress, "distress"},
+		{EventFireSale, "fire_sale"},
+		{EventRecovery, "recovery"},
+		{EventType(100), "unknown"},
+	}
+	for _, tt := range tests {
+		if got := tt.event.String(); got != tt.want {
+			t.Errorf("%d.String() = %q, want %q", tt.event, got, tt.want)
+		}
+	}
+}
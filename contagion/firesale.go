@@ -0,0 +1,139 @@
+package contagion
+
+import (
+	"math"
+	"sort"
+)
+
+// PriceImpact возвращает новую цену актива после продажи sold единиц,
+// когда всего в системе было outstanding единиц этого актива
+type PriceImpact func(price, sold, outstanding float64) float64
+
+// LinearImpact цена падает пропорционально проданной доле: p·(1 − α·sold/outstanding), но не ниже нуля
+func LinearImpact(alpha float64) PriceImpact {
+	return func(price, sold, outstanding float64) float64 {
+		if outstanding <= 0 {
+			return price
+		}
+		return math.Max(0, price*(1-alpha*sold/outstanding))
+	}
+}
+
+// ExponentialImpact цена падает экспоненциально от проданной доли: p·exp(−α·sold/outstanding)
+func ExponentialImpact(alpha float64) PriceImpact {
+	return func(price, sold, outstanding float64) float64 {
+		if outstanding <= 0 {
+			return price
+		}
+		return price * math.Exp(-alpha*sold/outstanding)
+	}
+}
+
+// Market рынок общих внешних активов для канала вынужденных продаж
+type Market struct {
+	Prices map[string]float64 // Начальные цены активов, в ходе каскада не меняются
+	Impact PriceImpact        // Функция влияния продаж на цену, по умолчанию ExponentialImpact(1)
+}
+
+// resetPrices восстанавливает текущие цены из начальных цен рынка перед новым каскадом
+func (s *BankSystem) resetPrices() {
+	s.prices = nil
+	if s.Market == nil {
+		return
+	}
+
+	s.prices = make(map[string]float64, len(s.Market.Prices))
+	for asset, price := range s.Market.Prices {
+		s.prices[asset] = price
+	}
+}
+
+// CurrentPrice возвращает текущую цену актива с учетом вынужденных продаж
+func (s *BankSystem) CurrentPrice(asset string) float64 {
+	if price, exists := s.prices[asset]; exists {
+		return price
+	}
+	if s.Market != nil {
+		return s.Market.Prices[asset]
+	}
+	return 0
+}
+
+// HoldingsValue возвращает рыночную стоимость портфеля внешних активов банка по текущим ценам
+func (s *BankSystem) HoldingsValue(name string) float64 {
+	total := 0.0
+	for asset, units := range s.Banks[name].Holdings {
+		total += units * s.CurrentPrice(asset)
+	}
+	return total
+}
+
+// fireSale продает активы банка seller на сумму amount (весь портфель, если amount <= 0),
+// зачисляет выручку в денежные средства продавца,
+// сдвигает цены через функцию влияния и переоценивает портфели всех держателей
+func (s *BankSystem) fireSale(seller string, amount float64, level int) {
+	if s.Market == nil {
+		return
+	}
+	if s.prices == nil {
+		s.resetPrices()
+	}
+	impact := s.Market.Impact
+	if impact == nil {
+		impact = ExponentialImpact(1)
+	}
+
+	holdings := s.Banks[seller].Holdings
+	value := s.HoldingsValue(seller)
+	if value <= 0 {
+		return
+	}
+	share := 1.0
+	if amount > 0 && amount < value {
+		share = amount / value
+	}
+
+	assets := make([]string, 0, len(holdings))
+	for asset := range holdings {
+		assets = append(assets, asset)
+	}
+	sort.Strings(assets)
+
+	for _, asset := range assets {
+		sold := holdings[asset] * share
+		if sold <= 0 {
+			continue
+		}
+
+		outstanding := 0.0
+		for _, bank := range s.Banks {
+			outstanding += bank.Holdings[asset]
+		}
+		holdings[asset] -= sold
+
+		// Продавец получает денежные средства по цене до продажи
+		oldPrice := s.CurrentPrice(asset)
+		s.adjustCash(seller, sold*oldPrice)
+		newPrice := impact(oldPrice, sold, outstanding)
+		s.prices[asset] = newPrice
+		drop := oldPrice - newPrice
+		if drop <= 0 {
+			continue
+		}
+
+		// Переоцениваем оставшиеся портфели всех держателей актива
+		holders := s.bankNames(func(name string) float64 {
+			return s.Banks[name].Holdings[asset]
+		})
+		for _, holderName := range holders {
+			holder := s.Banks[holderName]
+			loss := holder.Holdings[asset] * drop
+			if holder.Bankrupt || loss <= 0 {
+				continue
+			}
+			s.adjust(holderName, -loss)
+			s.emit(Event{Type: EventFireSale, Level: level, Bank: holderName, Counterparty: seller, Cause: seller, Amount: loss})
+			s.settle(holderName, level)
+		}
+	}
+}
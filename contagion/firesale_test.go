@@ -0,0 +1,47 @@
+package contagion
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Банкрот A продает весь портфель: при линейном влиянии цена падает на α·проданная доля,
+// и каждый держатель актива теряет на переоценке оставшихся единиц
+func TestFireSaleLinearImpact(t *testing.T) {
+	system := &BankSystem{
+		Market: &Market{Prices: map[string]float64{"bond": 10}, Impact: LinearImpact(0.5)},
+		Banks: map[string]Bank{
+			"A": {Balance: 10, Holdings: map[string]float64{"bond": 10}},
+			"B": {Balance: 100, Holdings: map[string]float64{"bond": 30}},
+			"C": {Balance: 100, Holdings: map[string]float64{"bond": 60}},
+		},
+	}
+	recorder := &Recorder{}
+	system.Subscribe(recorder)
+	system.StressTest("A")
+
+	// Продано 10 из 100 единиц: 10·(1 − 0.5·10/100) = 9.5
+	if price := system.CurrentPrice("bond"); price != 9.5 {
+		t.Errorf("price = %g, want 9.5", price)
+	}
+	if cash := system.Banks["A"].Cash; cash != 100 {
+		t.Errorf("cash of A = %g, want 100 from the sale at the old price", cash)
+	}
+
+	var sales []Event
+	for _, e := range recorder.Events {
+		if e.Type == EventFireSale {
+			sales = append(sales, e)
+		}
+	}
+	want := []Event{
+		{Type: EventFireSale, Bank: "B", Counterparty: "A", Cause: "A", Amount: 15, Balance: 85, CounterpartyBalance: -1},
+		{Type: EventFireSale, Bank: "C", Counterparty: "A", Cause: "A", Amount: 30, Balance: 70, CounterpartyBalance: -1},
+	}
+	if !reflect.DeepEqual(sales, want) {
+		t.Errorf("fire sale events = %+v, want %+v", sales, want)
+	}
+	if defaults := system.DefaultCount(); defaults != 1 {
+		t.Errorf("defaults = %d, want 1", defaults)
+	}
+}
@@ -0,0 +1,43 @@
+package contagion
+
+import "fmt"
+
+// Model модель распространения банкротств, которую использует StressTest
+type Model int
+
+const (
+	// ModelCascade каскад с потерей фиксированной доли требований (LambdaC, LambdaF) и набегом вкладчиков
+	ModelCascade Model = iota
+	// ModelEisenbergNoe клиринговый платежный вектор Айзенберга–Ноэ
+	ModelEisenbergNoe
+	// ModelDebtRank распространение частичного дистресса DebtRank
+	ModelDebtRank
+)
+
+// String возвращает имя модели
+func (m Model) String() string {
+	switch m {
+	case ModelCascade:
+		return "cascade"
+	case ModelEisenbergNoe:
+		return "eisenberg-noe"
+	case ModelDebtRank:
+		return "debtrank"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseModel разбирает модель из строки "cascade", "eisenberg-noe" или "debtrank"
+func ParseModel(model string) (Model, error) {
+	switch model {
+	case "cascade":
+		return ModelCascade, nil
+	case "eisenberg-noe":
+		return ModelEisenbergNoe, nil
+	case "debtrank":
+		return ModelDebtRank, nil
+	default:
+		return 0, fmt.Errorf("неизвестная модель: %q", model)
+	}
+}
@@ -0,0 +1,109 @@
+package contagion
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+// OrderKind способ упорядочивания банков при обработке каскада
+type OrderKind int
+
+const (
+	OrderByName     OrderKind = iota // По имени банка
+	OrderByExposure                  // По убыванию размера требования, при равенстве по имени
+	OrderRandom                      // Случайная перестановка с заданным зерном
+)
+
+// Order задает порядок применения шоков внутри уровня каскада и фиксируется для каждого прогона.
+// В режиме UpdateImmediate банк выбывает, как только шок сделал его банкротом, поэтому от порядка
+// зависит, какие банки обанкротятся. В последовательном и синхронном режимах банкротства проверяются
+// в конце уровня, и порядок меняет последовательность событий и путь цен при вынужденных продажах
+type Order struct {
+	Kind OrderKind
+	Seed int64 // Зерно генератора для OrderRandom
+}
+
+// String возвращает описание порядка, пригодное для записи вместе с результатами
+func (o Order) String() string {
+	switch o.Kind {
+	case OrderByName:
+		return "name"
+	case OrderByExposure:
+		return "exposure"
+	case OrderRandom:
+		return fmt.Sprintf("random(seed=%d)", o.Seed)
+	default:
+		return "unknown"
+	}
+}
+
+// ParseOrder разбирает порядок из строки вида "name", "exposure" или "random"
+func ParseOrder(kind string, seed int64) (Order, error) {
+	switch kind {
+	case "name":
+		return Order{Kind: OrderByName}, nil
+	case "exposure":
+		return Order{Kind: OrderByExposure}, nil
+	case "random":
+		return Order{Kind: OrderRandom, Seed: seed}, nil
+	default:
+		return Order{}, fmt.Errorf("неизвестный порядок обработки: %q", kind)
+	}
+}
+
+// resetOrder начинает новую последовательность случайных перестановок,
+// чтобы каждый каскад с одинаковым зерном воспроизводился одинаково
+func (s *BankSystem) resetOrder() {
+	s.rng = rand.New(rand.NewSource(s.Order.Seed))
+}
+
+// ordered возвращает имена в порядке обработки. weight задает размер требования
+// для OrderByExposure
+func (s *BankSystem) ordered(names []string, weight func(name string) float64) []string {
+	sort.Strings(names)
+
+	switch s.Order.Kind {
+	case OrderByExposure:
+		sort.SliceStable(names, func(i, j int) bool {
+			return weight(names[i]) > weight(names[j])
+		})
+	case OrderRandom:
+		if s.rng == nil {
+			s.resetOrder()
+		}
+		s.rng.Shuffle(len(names), func(i, j int) {
+			names[i], names[j] = names[j], names[i]
+		})
+	}
+	return names
+}
+
+// bankNames возвращает имена всех банков системы в порядке обработки
+func (s *BankSystem) bankNames(weight func(name string) float64) []string {
+	names := make([]string, 0, len(s.Banks))
+	for name := range s.Banks {
+		names = append(names, name)
+	}
+	return s.ordered(names, weight)
+}
+
+// dependencyNames возвращает должников банка в порядке обработки, весом служит размер требования
+func (s *BankSystem) dependencyNames(dependencies map[string]float64) []string {
+	names := make([]string, 0, len(dependencies))
+	for name := range dependencies {
+		names = append(names, name)
+	}
+	return s.ordered(names, func(name string) float64 {
+		return dependencies[name]
+	})
+}
+
+// totalExposure возвращает сумму всех требований банка к другим банкам
+func (s *BankSystem) totalExposure(name string) float64 {
+	total := 0.0
+	for _, amount := range s.Banks[name].Dependencies {
+		total += amount
+	}
+	return total
+}
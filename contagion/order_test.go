@@ -0,0 +1,93 @@
+package contagion
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// mutualDeposits возвращает систему, в которой у банкрота A два партнера X и Y с взаимными вкладами.
+// В режиме UpdateImmediate набег на партнера, который обрабатывается первым, делает его банкротом, и он уже не забирает
+// свой вклад у второго. У Y требований больше, поэтому при OrderByExposure он идет первым
+func mutualDeposits(order Order, update UpdateMode) *BankSystem {
+	return &BankSystem{
+		EnablePanic: true,
+		PanicRate:   0.6,
+		Order:       order,
+		Update:      update,
+		Banks: map[string]Bank{
+			"A": {Balance: 10, Dependencies: map[string]float64{"X": 1, "Y": 1}},
+			"X": {Balance: 50, Dependencies: map[string]float64{"Y": 100}},
+			"Y": {Balance: 50, Dependencies: map[string]float64{"X": 100, "Z": 10}},
+			"Z": {Balance: 1000},
+		},
+	}
+}
+
+// bankrupts возвращает отсортированные имена обанкротившихся банков
+func bankrupts(s *BankSystem) []string {
+	names := make([]string, 0)
+	for name, bank := range s.Banks {
+		if bank.Bankrupt {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestOrderDecidesImmediateDefaults(t *testing.T) {
+	tests := []struct {
+		name   string
+		order  Order
+		update UpdateMode
+		want   []string
+	}{
+		{"immediate by name", Order{Kind: OrderByName}, UpdateImmediate, []string{"A", "X"}},
+		{"immediate by exposure", Order{Kind: OrderByExposure}, UpdateImmediate, []string{"A", "Y"}},
+		{"sequential by name", Order{Kind: OrderByName}, UpdateSequential, []string{"A"}},
+		{"sequential by exposure", Order{Kind: OrderByExposure}, UpdateSequential, []string{"A"}},
+		{"synchronous by name", Order{Kind: OrderByName}, UpdateSynchronous, []string{"A"}},
+		{"synchronous by exposure", Order{Kind: OrderByExposure}, UpdateSynchronous, []string{"A"}},
+		{"synchronous random", Order{Kind: OrderRandom, Seed: 7}, UpdateSynchronous, []string{"A"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			system := mutualDeposits(tt.order, tt.update)
+			system.StressTest("A")
+			if got := bankrupts(system); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("bankrupt = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRandomOrderIsReproducible(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		first := mutualDeposits(Order{Kind: OrderRandom, Seed: seed}, UpdateImmediate)
+		second := mutualDeposits(Order{Kind: OrderRandom, Seed: seed}, UpdateImmediate)
+		first.StressTest("A")
+		second.StressTest("A")
+		if a, b := bankrupts(first), bankrupts(second); !reflect.DeepEqual(a, b) {
+			t.Errorf("seed %d: %v and %v", seed, a, b)
+		}
+	}
+}
+
+// Потери, ровно равные капиталу, не делают банк банкротом, в каком бы порядке их ни сложить
+func TestZeroBalanceIsNotDefault(t *testing.T) {
+	for _, kind := range []OrderKind{OrderByName, OrderByExposure, OrderRandom} {
+		system := &BankSystem{
+			LambdaF: 1,
+			Order:   Order{Kind: kind, Seed: 1},
+			Banks: map[string]Bank{
+				"A1": {Balance: 10, Dependencies: map[string]float64{"B": 0.1}},
+				"A2": {Balance: 10, Dependencies: map[string]float64{"B": 0.2}},
+				"B":  {Balance: 0.3},
+			},
+		}
+		if defaults := system.Shock([]string{"A1", "A2"}, nil); defaults != 2 {
+			t.Errorf("%s: defaults = %d, want 2 (balance of B = %g)", system.Order, defaults, system.Banks["B"].Balance)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package contagion
+
+import "sort"
+
+// Importance последствия банкротства одного банка для всей системы
+type Importance struct {
+	Bank     string  // Банк, объявленный банкротом
+	Defaults int     // Количество банкротств, включая сам банк
+	Loss     float64 // Суммарное снижение балансов всех банков
+	Rank     int     // Место в рейтинге, 1 - самый системно значимый банк
+}
+
+// RankImportance по очереди объявляет банкротом каждый банк на свежей копии системы
+// и возвращает рейтинг по убыванию количества банкротств, а при равенстве по убыванию потерь
+func (s *BankSystem) RankImportance() []Importance {
+	ranking := make([]Importance, 0, len(s.Banks))
+	for name := range s.Banks {
+		system := s.Clone()
+		defaults := system.StressTest(name)
+		ranking = append(ranking, Importance{
+			Bank:     name,
+			Defaults: defaults,
+			Loss:     system.TotalLoss(s.Banks),
+		})
+	}
+
+	sort.Slice(ranking, func(i, j int) bool {
+		if ranking[i].Defaults != ranking[j].Defaults {
+			return ranking[i].Defaults > ranking[j].Defaults
+		}
+		if ranking[i].Loss != ranking[j].Loss {
+			return ranking[i].Loss > ranking[j].Loss
+		}
+		return ranking[i].Bank < ranking[j].Bank
+	})
+	// Банки с одинаковыми последствиями делят одно место
+	for i := range ranking {
+		if i > 0 && ranking[i].Defaults == ranking[i-1].Defaults && ranking[i].Loss == ranking[i-1].Loss {
+			ranking[i].Rank = ranking[i-1].Rank
+		} else {
+			ranking[i].Rank = i + 1
+		}
+	}
+	return ranking
+}
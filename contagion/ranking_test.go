@@ -0,0 +1,39 @@
+package contagion
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Банкротство центра звезды H обрушивает все листья, банкротство листа задевает только сам лист.
+// Листья симметричны и делят одно место в рейтинге, а исходная система не меняется
+func TestRankImportance(t *testing.T) {
+	star := func() *BankSystem {
+		return &BankSystem{
+			LambdaC: 0.5,
+			LambdaF: 0.5,
+			Banks: map[string]Bank{
+				"H":  {Balance: 1000, Dependencies: map[string]float64{"L1": 100, "L2": 100, "L3": 100}},
+				"L1": {Balance: 10},
+				"L2": {Balance: 10},
+				"L3": {Balance: 10},
+			},
+		}
+	}
+	system := star()
+
+	want := []Importance{
+		// H: 1000 → -1, каждый лист теряет 50 на шоке фондирования
+		{Bank: "H", Defaults: 4, Loss: 1001 + 3*50, Rank: 1},
+		// Лист: 10 → -1, H теряет 50 на кредитном шоке
+		{Bank: "L1", Defaults: 1, Loss: 11 + 50, Rank: 2},
+		{Bank: "L2", Defaults: 1, Loss: 11 + 50, Rank: 2},
+		{Bank: "L3", Defaults: 1, Loss: 11 + 50, Rank: 2},
+	}
+	if got := system.RankImportance(); !reflect.DeepEqual(got, want) {
+		t.Errorf("RankImportance() = %+v, want %+v", got, want)
+	}
+	if !reflect.DeepEqual(system.Banks, star().Banks) {
+		t.Errorf("RankImportance changed the system: %+v", system.Banks)
+	}
+}
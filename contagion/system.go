@@ -0,0 +1,115 @@
+// Package contagion содержит модель распространения банкротств в межбанковской системе,
+// общую для визуализатора и для перебора параметров
+package contagion
+
+import "math/rand"
+
+// BankSystem представляет основной объект алгоритма
+type BankSystem struct {
+	LambdaC     float64 // Параметр lambda для кредитного шока
+	LambdaF     float64 // Параметр lambda для шока фондирования
+	EnablePanic bool    // Параметр для включения / выключения паники
+	PanicRate   float64 // Параметр p для доли закрываемых вкладов
+
+	// EnableLiquidity включает учет денежных средств: при набеге вкладчики забирают денежные средства
+	// и уменьшают свои требования к банку, не затрагивая капитал, и банк без денежных средств
+	// банкротится по ликвидности. Без него набег, как в исходной модели, списывается из капитала
+	EnableLiquidity bool
+
+	// CapitalRatio доля активов, которую составляет капитальный буфер банка в модели Гаи–Кападиа.
+	// Ноль означает, что банк банкротится при отрицательном балансе
+	CapitalRatio float64
+
+	Model  Model      // Модель распространения банкротств
+	Order  Order      // Порядок применения шоков внутри уровня каскада
+	Update UpdateMode // Режим применения шоков: последовательно или синхронно по уровням
+
+	Banks  map[string]Bank
+	Market *Market // Рынок внешних активов для канала вынужденных продаж (может быть nil)
+
+	// Clearing результат последнего расчета модели Айзенберга–Ноэ (nil, если она не запускалась)
+	Clearing *ClearingResult
+	// DebtRankResult результат последнего расчета DebtRank (nil, если он не запускался)
+	DebtRankResult *DebtRankResult
+
+	observers []Observer
+	rng       *rand.Rand
+	pending   map[string]change
+	failed    []string // Банки, признанные банкротами по ходу текущего уровня каскада
+	buffers   map[string]float64
+	prices    map[string]float64
+}
+
+// Bank представляет банк в банковской системе
+type Bank struct {
+	Label          string             // Подпись банка для отображения (может быть пустой)
+	Balance        float64            // Собственный капитал банка
+	Cash           float64            // Денежные средства, из которых выплачиваются вклады при набеге
+	Deposits       float64            // Вклады клиентов
+	Dependencies   map[string]float64 // Сколько банк вложил в каждого из своих должников
+	CreditLoss     map[string]float64 // Доля потерь по требованию к должнику при его банкротстве, переопределяет LambdaC
+	FundingLoss    map[string]float64 // Доля, которую теряет должник при банкротстве банка, переопределяет LambdaF
+	Holdings       map[string]float64 // Портфель общих внешних активов: количество единиц каждого актива
+	ExternalAssets float64            // Внешние (не межбанковские) активы без портфеля Holdings, ноль - вывести из Balance
+	InterbankLoss  float64            // Накопленные потери на межбанковских требованиях (кредитные шоки)
+	ExternalLoss   float64            // Накопленные потери на внешних активах (шоки фондирования, начальные потери)
+	Bankrupt       bool
+	DefaultKind    DefaultKind // Причина банкротства
+	Distress       float64     // Уровень дистресса из DebtRank: 0 - банк не затронут, 1 - дефолт
+	X, Y           float64     // Координаты банка для визуализации
+}
+
+// Clone возвращает копию системы с теми же параметрами и глубокой копией банков, без подписчиков
+func (s *BankSystem) Clone() *BankSystem {
+	return &BankSystem{
+		LambdaC:         s.LambdaC,
+		LambdaF:         s.LambdaF,
+		EnablePanic:     s.EnablePanic,
+		PanicRate:       s.PanicRate,
+		EnableLiquidity: s.EnableLiquidity,
+		CapitalRatio:    s.CapitalRatio,
+		Model:           s.Model,
+		Order:           s.Order,
+		Update:          s.Update,
+		Banks:           CloneBanks(s.Banks),
+		Market:          s.Market,
+	}
+}
+
+// CloneBanks возвращает глубокую копию банков, чтобы каждый прогон не портил исходную систему
+func CloneBanks(original map[string]Bank) map[string]Bank {
+	clonedBanks := make(map[string]Bank)
+	for k, v := range original {
+		v.Dependencies = cloneAmounts(v.Dependencies)
+		v.CreditLoss = cloneAmounts(v.CreditLoss)
+		v.FundingLoss = cloneAmounts(v.FundingLoss)
+		v.Holdings = cloneAmounts(v.Holdings)
+		clonedBanks[k] = v
+	}
+	return clonedBanks
+}
+
+// cloneAmounts копирует словарь сумм
+func cloneAmounts(original map[string]float64) map[string]float64 {
+	amountsCopy := make(map[string]float64, len(original))
+	for k, v := range original {
+		amountsCopy[k] = v
+	}
+	return amountsCopy
+}
+
+// CreditLossRate возвращает долю потерь кредитора по требованию к обанкротившемуся должнику
+func (s *BankSystem) CreditLossRate(creditor, debtor string) float64 {
+	if rate, exists := s.Banks[creditor].CreditLoss[debtor]; exists {
+		return rate
+	}
+	return s.LambdaC
+}
+
+// FundingLossRate возвращает долю средств, которую теряет должник при банкротстве кредитора
+func (s *BankSystem) FundingLossRate(creditor, debtor string) float64 {
+	if rate, exists := s.Banks[creditor].FundingLoss[debtor]; exists {
+		return rate
+	}
+	return s.LambdaF
+}
@@ -0,0 +1,51 @@
+package contagion
+
+import "testing"
+
+// Доли потерь отдельных требований заменяют LambdaC и LambdaF в размере шоков
+func TestLossRateOverrides(t *testing.T) {
+	tests := []struct {
+		name        string
+		creditLoss  map[string]float64 // CreditLoss банка B
+		fundingLoss map[string]float64 // FundingLoss банка A
+		credit      float64            // Ожидаемый кредитный шок B
+		funding     float64            // Ожидаемый шок фондирования C
+	}{
+		{name: "lambdas", credit: 50, funding: 50},
+		{name: "credit override", creditLoss: map[string]float64{"A": 0.1}, credit: 10, funding: 50},
+		{name: "funding override", fundingLoss: map[string]float64{"C": 0.2}, credit: 50, funding: 20},
+		{name: "zero overrides", creditLoss: map[string]float64{"A": 0}, fundingLoss: map[string]float64{"C": 0}},
+	}
+	for _, tt := range tests {
+		// B вложил 100 в A, A вложил 100 в C
+		system := &BankSystem{
+			LambdaC: 0.5,
+			LambdaF: 0.5,
+			Banks: map[string]Bank{
+				"A": {Balance: 10, Dependencies: map[string]float64{"C": 100}, FundingLoss: tt.fundingLoss},
+				"B": {Balance: 1000, Dependencies: map[string]float64{"A": 100}, CreditLoss: tt.creditLoss},
+				"C": {Balance: 1000},
+			},
+		}
+		recorder := &Recorder{}
+		system.Subscribe(recorder)
+		system.StressTest("A")
+
+		shocks := make(map[EventType]float64)
+		for _, e := range recorder.Events {
+			shocks[e.Type] += e.Amount
+		}
+		if shocks[EventCreditShock] != tt.credit {
+			t.Errorf("%s: credit shock = %g, want %g", tt.name, shocks[EventCreditShock], tt.credit)
+		}
+		if shocks[EventFundingShock] != tt.funding {
+			t.Errorf("%s: funding shock = %g, want %g", tt.name, shocks[EventFundingShock], tt.funding)
+		}
+		if balance := system.Banks["B"].Balance; balance != 1000-tt.credit {
+			t.Errorf("%s: balance of B = %g, want %g", tt.name, balance, 1000-tt.credit)
+		}
+		if balance := system.Banks["C"].Balance; balance != 1000-tt.funding {
+			t.Errorf("%s: balance of C = %g, want %g", tt.name, balance, 1000-tt.funding)
+		}
+	}
+}
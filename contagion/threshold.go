@@ -0,0 +1,35 @@
+package contagion
+
+// balanceTolerance допуск при сравнении балансов и денежных средств с нулем, чтобы исход
+// не зависел от ошибок округления, которые дает порядок суммирования шоков
+const balanceTolerance = 1e-9
+
+// captureBuffers фиксирует капитальный буфер каждого банка до начала каскада
+// для пороговой модели Гаи–Кападиа
+func (s *BankSystem) captureBuffers() {
+	s.buffers = nil
+	if s.CapitalRatio <= 0 {
+		return
+	}
+
+	s.buffers = make(map[string]float64, len(s.Banks))
+	for name := range s.Banks {
+		s.buffers[name] = s.CapitalRatio * s.totalAssets(name)
+	}
+}
+
+// totalAssets возвращает все активы банка по его балансу
+func (s *BankSystem) totalAssets(name string) float64 {
+	return s.BalanceSheet(name).Assets()
+}
+
+// insolvent проверяет, должен ли банк быть признан банкротом.
+// При заданном CapitalRatio банк банкротится, когда потери на межбанковских активах
+// превышают капитальный буфер (модель Гаи–Кападиа), иначе когда баланс становится отрицательным
+func (s *BankSystem) insolvent(name string) bool {
+	bank := s.Banks[name]
+	if s.buffers != nil {
+		return bank.InterbankLoss > s.buffers[name]+balanceTolerance
+	}
+	return bank.Balance < -balanceTolerance
+}
@@ -0,0 +1,41 @@
+package contagion
+
+import "testing"
+
+// В модели Гаи–Кападиа банк банкротится, когда потери на межбанковских активах превышают
+// CapitalRatio от всех активов, независимо от знака баланса
+func TestCapitalRatioThreshold(t *testing.T) {
+	tests := []struct {
+		name         string
+		balance      float64
+		lambda       float64
+		capitalRatio float64
+		bankrupt     bool
+	}{
+		// Активы B: 100 требований к A и 100 внешних активов, буфер 0.2·200 = 40
+		{name: "loss above buffer, positive balance", balance: 60, lambda: 0.5, capitalRatio: 0.2, bankrupt: true},
+		{name: "loss below buffer, negative balance", balance: 20, lambda: 0.3, capitalRatio: 0.2, bankrupt: false},
+		{name: "loss equal to buffer", balance: 60, lambda: 0.4, capitalRatio: 0.2, bankrupt: false},
+		// Без буфера решает знак баланса
+		{name: "no buffer, positive balance", balance: 60, lambda: 0.5, bankrupt: false},
+		{name: "no buffer, negative balance", balance: 20, lambda: 0.3, bankrupt: true},
+	}
+	for _, tt := range tests {
+		system := &BankSystem{
+			LambdaC:      tt.lambda,
+			CapitalRatio: tt.capitalRatio,
+			Banks: map[string]Bank{
+				"A": {Balance: 10},
+				"B": {Balance: tt.balance, ExternalAssets: 100, Dependencies: map[string]float64{"A": 100}},
+			},
+		}
+		system.StressTest("A")
+		b := system.Banks["B"]
+		if b.Bankrupt != tt.bankrupt {
+			t.Errorf("%s: bankrupt = %t, want %t (balance %g, interbank loss %g)", tt.name, b.Bankrupt, tt.bankrupt, b.Balance, b.InterbankLoss)
+		}
+		if want := tt.balance - tt.lambda*100; b.Balance != want {
+			t.Errorf("%s: balance = %g, want %g", tt.name, b.Balance, want)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package contagion
+
+import (
+	"fmt"
+	"sort"
+)
+
+// UpdateMode определяет, как шоки одного уровня каскада применяются к балансам
+type UpdateMode int
+
+const (
+	// UpdateSequential каждый шок сразу меняет баланс, последующие шоки уровня видят изменения предыдущих.
+	// Новые банкротства проверяются в конце уровня (исходная модель)
+	UpdateSequential UpdateMode = iota
+	// UpdateSynchronous все шоки уровня считаются по одному снимку и применяются разом в конце уровня.
+	// Исключение - цены внешних активов, которые вынужденные продажи сдвигают сразу
+	UpdateSynchronous
+	// UpdateImmediate как UpdateSequential, но банк, которого шок сделал банкротом, выбывает сразу:
+	// больше не получает шоков уровня и не забирает вклады. Результат зависит от порядка обработки
+	UpdateImmediate
+)
+
+// String возвращает имя режима обновления
+func (m UpdateMode) String() string {
+	switch m {
+	case UpdateSequential:
+		return "sequential"
+	case UpdateSynchronous:
+		return "synchronous"
+	case UpdateImmediate:
+		return "immediate"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseUpdateMode разбирает режим обновления из строки "sequential", "synchronous" или "immediate"
+func ParseUpdateMode(mode string) (UpdateMode, error) {
+	switch mode {
+	case "sequential":
+		return UpdateSequential, nil
+	case "synchronous":
+		return UpdateSynchronous, nil
+	case "immediate":
+		return UpdateImmediate, nil
+	default:
+		return 0, fmt.Errorf("неизвестный режим обновления: %q", mode)
+	}
+}
+
+// change изменение банка, которое в синхронном режиме откладывается до конца уровня
+type change struct {
+	balance       float64
+	cash          float64
+	interbankLoss float64
+	externalLoss  float64
+	claims        map[string]float64 // Изменения требований к должникам (Dependencies)
+}
+
+// add складывает два изменения
+func (c change) add(other change) change {
+	sum := change{
+		balance:       c.balance + other.balance,
+		cash:          c.cash + other.cash,
+		interbankLoss: c.interbankLoss + other.interbankLoss,
+		externalLoss:  c.externalLoss + other.externalLoss,
+	}
+	if len(c.claims)+len(other.claims) > 0 {
+		sum.claims = make(map[string]float64)
+		for _, claims := range []map[string]float64{c.claims, other.claims} {
+			for debtor, delta := range claims {
+				sum.claims[debtor] += delta
+			}
+		}
+	}
+	return sum
+}
+
+// applyTo применяет изменение к банку
+func (c change) applyTo(bank *Bank) {
+	bank.Balance += c.balance
+	bank.Cash += c.cash
+	bank.InterbankLoss += c.interbankLoss
+	bank.ExternalLoss += c.externalLoss
+	for debtor, delta := range c.claims {
+		bank.Dependencies[debtor] += delta
+	}
+}
+
+// apply изменяет банк: сразу в последовательном и немедленном режимах или откладывает изменение до конца
+// уровня в синхронном. Цены внешних активов при вынужденных продажах меняются сразу во всех режимах: каждая продажа
+// сдвигает цену для следующих продаж того же уровня
+func (s *BankSystem) apply(name string, c change) {
+	if s.Update == UpdateSynchronous {
+		if s.pending == nil {
+			s.pending = make(map[string]change)
+		}
+		s.pending[name] = s.pending[name].add(c)
+		return
+	}
+
+	bank := s.Banks[name]
+	c.applyTo(&bank)
+	s.Banks[name] = bank
+}
+
+// adjust изменяет баланс банка
+func (s *BankSystem) adjust(name string, delta float64) {
+	s.apply(name, change{balance: delta})
+}
+
+// adjustCash изменяет денежные средства банка
+func (s *BankSystem) adjustCash(name string, delta float64) {
+	s.apply(name, change{cash: delta})
+}
+
+// impair списывает потери на межбанковских требованиях банка из его баланса
+func (s *BankSystem) impair(name string, loss float64) {
+	s.apply(name, change{balance: -loss, interbankLoss: loss})
+}
+
+// writeDown списывает потери на внешних активах банка из его баланса
+func (s *BankSystem) writeDown(name string, loss float64) {
+	s.apply(name, change{balance: -loss, externalLoss: loss})
+}
+
+// withdraw забирает amount из вклада банка depositor в банке bank: денежные средства переходят
+// от bank к depositor, а требование depositor к bank уменьшается. Капитал обоих банков не меняется
+func (s *BankSystem) withdraw(depositor, bank string, amount float64) {
+	s.apply(bank, change{cash: -amount})
+	s.apply(depositor, change{cash: amount, claims: map[string]float64{bank: -amount}})
+}
+
+// settle в режиме UpdateImmediate сразу признает банкротом банк, к которому только что применен шок,
+// если он стал неплатежеспособным или у него кончились денежные средства. Банк попадает на следующий
+// уровень каскада. В остальных режимах все банкротства проверяются в конце уровня
+func (s *BankSystem) settle(name string, level int) {
+	if s.Update != UpdateImmediate || s.Banks[name].Bankrupt {
+		return
+	}
+	if kind := s.defaultKind(name); kind != NoDefault {
+		s.markDefault(name, kind, level+1)
+		s.failed = append(s.failed, name)
+	}
+}
+
+// flush применяет отложенные изменения синхронного режима
+func (s *BankSystem) flush() {
+	names := make([]string, 0, len(s.Banks))
+	for name := range s.Banks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		bank := s.Banks[name]
+		s.pending[name].applyTo(&bank)
+		s.Banks[name] = bank
+	}
+	s.pending = nil
+}
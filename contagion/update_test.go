@@ -0,0 +1,76 @@
+package contagion
+
+import "testing"
+
+func TestUpdateModesDiffer(t *testing.T) {
+	tests := []struct {
+		update   UpdateMode
+		defaults int
+	}{
+		// Немедленно: набег на X делает его банкротом раньше, чем он заберет свой вклад у Y
+		{UpdateImmediate, 2},
+		// Банкротства в конце уровня: X и Y забирают вклады друг у друга, и оба остаются с балансом 50
+		{UpdateSequential, 1},
+		{UpdateSynchronous, 1},
+	}
+	for _, tt := range tests {
+		system := mutualDeposits(Order{Kind: OrderByName}, tt.update)
+		if got := system.StressTest("A"); got != tt.defaults {
+			t.Errorf("%s: defaults = %d, want %d", tt.update, got, tt.defaults)
+		}
+		if tt.defaults > 1 {
+			continue
+		}
+		for _, name := range []string{"X", "Y"} {
+			if balance := system.Banks[name].Balance; balance != 50 {
+				t.Errorf("%s: balance of %s = %g, want 50", tt.update, name, balance)
+			}
+		}
+	}
+}
+
+func TestParseUpdateMode(t *testing.T) {
+	for _, mode := range []UpdateMode{UpdateSequential, UpdateSynchronous, UpdateImmediate} {
+		if got, err := ParseUpdateMode(mode.String()); err != nil || got != mode {
+			t.Errorf("ParseUpdateMode(%q) = %s, %v", mode, got, err)
+		}
+	}
+	if _, err := ParseUpdateMode("parallel"); err == nil {
+		t.Error("no error for an unknown mode")
+	}
+}
+
+// В синхронном режиме потери на межбанковских требованиях применяются только в конце уровня
+func TestSynchronousBuffersInterbankLoss(t *testing.T) {
+	tests := []struct {
+		update UpdateMode
+		want   float64 // InterbankLoss банка B в момент кредитного шока
+	}{
+		{UpdateSequential, 50},
+		{UpdateSynchronous, 0},
+	}
+	for _, tt := range tests {
+		system := &BankSystem{
+			LambdaC: 0.5,
+			Update:  tt.update,
+			Banks: map[string]Bank{
+				"A": {Balance: 10},
+				"B": {Balance: 100, Dependencies: map[string]float64{"A": 100}},
+			},
+		}
+		seen := -1.0
+		system.Subscribe(ObserverFunc(func(e Event) {
+			if e.Type == EventCreditShock {
+				seen = system.Banks[e.Bank].InterbankLoss
+			}
+		}))
+		system.StressTest("A")
+
+		if seen != tt.want {
+			t.Errorf("%s: interbank loss during the level = %g, want %g", tt.update, seen, tt.want)
+		}
+		if loss := system.Banks["B"].InterbankLoss; loss != 50 {
+			t.Errorf("%s: interbank loss after the cascade = %g, want 50", tt.update, loss)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+// Package locale содержит каталог сообщений интерфейса и отчетов на русском и английском
+package locale
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// EnvVar переменная окружения, из которой берется язык, если он не задан флагом
+const EnvVar = "BANK_LANG"
+
+// Catalog сообщения одного языка. Нулевой указатель означает русский каталог
+type Catalog struct {
+	lang     string
+	messages map[string]string
+}
+
+// New возвращает каталог языка lang. Пустая строка означает язык из EnvVar, а если она не задана - русский
+func New(lang string) (*Catalog, error) {
+	if lang == "" {
+		lang = os.Getenv(EnvVar)
+	}
+	if lang == "" {
+		lang = "ru"
+	}
+
+	// Из значений вида en_US.UTF-8 берется только код языка
+	lang = strings.ToLower(lang)
+	if i := strings.IndexAny(lang, "_-."); i >= 0 {
+		lang = lang[:i]
+	}
+
+	messages, exists := catalogs[lang]
+	if !exists {
+		return nil, fmt.Errorf("неизвестный язык: %q, доступны: %s", lang, strings.Join(Languages(), ", "))
+	}
+	return &Catalog{lang: lang, messages: messages}, nil
+}
+
+// Languages возвращает коды доступных языков
+func Languages() []string {
+	langs := make([]string, 0, len(catalogs))
+	for lang := range catalogs {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// Lang возвращает код языка каталога
+func (c *Catalog) Lang() string {
+	if c == nil {
+		return "ru"
+	}
+	return c.lang
+}
+
+// T возвращает сообщение key, подставляя в него args как в fmt.Sprintf.
+// Если в каталоге нет сообщения, берется русское, а если нет и его - сам ключ
+func (c *Catalog) T(key string, args ...any) string {
+	format, exists := russian[key]
+	if c != nil {
+		if message, found := c.messages[key]; found {
+			format, exists = message, true
+		}
+	}
+	if !exists {
+		format = key
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// catalogs каталоги по кодам языков
+var catalogs = map[string]map[string]string{
+	"ru": russian,
+	"en": english,
+}
+
+var russian = map[string]string{
+	"event.default":          "Банк %s обанкротился (%s)",
+	"event.funding_shock":    "Шок фондирования в связи с банкротсвом банка %s: Банк %s потерял %.2f",
+	"event.credit_shock":     "Кредитный шок в связи с банкротсвом банка %s: Банк %s потерял %.2f",
+	"event.bank_run":         "Набег вкладчиков: Банк %s забирает %.2f из своего вклада в банк %s в связи с банкротством банка %s",
+	"event.fire_sale":        "Вынужденная продажа активов банка %s: Банк %s потерял %.2f на переоценке",
+	"event.distress_initial": "Банк %s получает начальный дистресс %.2f",
+	"event.distress":         "Дистресс банка %s растет на %.2f из-за дистресса банка %s",
+	"event.recovery":         "Клиринг: банк-банкрот %s выплачивает банку %s %.2f",
+	"event.initial_loss":     "Начальный шок: Банк %s потерял %.2f",
+
+	"default_kind.initial":   "Исходное банкротство",
+	"default_kind.solvency":  "Неплатежеспособность",
+	"default_kind.liquidity": "Нехватка ликвидности",
+
+	"step.initial":      "Начальное состояние банковской системы",
+	"step.start_failed": "Начало стресс-теста: банкротами объявляются %s",
+	"step.start_losses": "Начало стресс-теста: банки получают начальные потери",
+	"step.finished":     "Стресс-тест завершен",
+
+	"hint.next":    "Нажмите Enter для продолжения",
+	"hint.exit":    "Нажмите Esc для выхода",
+	"window.title": "Визуализация банковской системы",
+
+	"bank.balance_sheet": "МБК: %.0f / %.0f\nВнешн.: %.0f\nКасса: %.0f  Вклады: %.0f",
+
+	"report.title":     "Стресс-тест",
+	"report.parameter": "Параметр",
+	"report.value":     "Значение",
+	"report.defaults":  "Обанкротилось банков: %d из %d",
+	"report.total":     "Итого",
+
+	"report.column.bank":            "Банк",
+	"report.column.initial_balance": "Начальный баланс",
+	"report.column.final_balance":   "Итоговый баланс",
+	"report.column.initial_loss":    "Начальный шок",
+	"report.column.credit_loss":     "Кредитный шок",
+	"report.column.funding_loss":    "Шок фондирования",
+	"report.column.bank_run_loss":   "Отток при набеге",
+	"report.column.bank_run_gain":   "Приток при набеге",
+	"report.column.fire_sale_loss":  "Вынужденные продажи",
+	"report.column.total_loss":      "Чистые потери",
+	"report.column.default_round":   "Раунд банкротства",
+	"report.column.bankrupt":        "Банкрот",
+	"report.column.default_kind":    "Причина",
+	"report.column.liabilities":     "Обязательства",
+	"report.column.payment":         "Клиринговый платеж",
+	"report.column.recovery_rate":   "Доля возврата",
+	"report.column.recovered":       "Получено от должников",
+
+	"report.bankrupt.true":  "Да",
+	"report.bankrupt.false": "Нет",
+
+	"heatmap.defaults": "Количество банкротств (%s)",
+	"heatmap.diff":     "Разность количества банкротств: full - ring",
+}
+
+var english = map[string]string{
+	"event.default":          "Bank %s defaulted (%s)",
+	"event.funding_shock":    "Funding shock after the default of bank %s: bank %s lost %.2f",
+	"event.credit_shock":     "Credit shock after the default of bank %s: bank %s lost %.2f",
+	"event.bank_run":         "Bank run: bank %s withdraws %.2f of its deposit in bank %s after the default of bank %s",
+	"event.fire_sale":        "Fire sale of bank %s assets: bank %s lost %.2f on revaluation",
+	"event.distress_initial": "Bank %s receives initial distress %.2f",
+	"event.distress":         "Distress of bank %s grows by %.2f because of the distress of bank %s",
+	"event.recovery":         "Clearing: defaulted bank %s pays %[3].2f to bank %[2]s",
+	"event.initial_loss":     "Initial shock: bank %s lost %.2f",
+
+	"default_kind.initial":   "Initial default",
+	"default_kind.solvency":  "Insolvency",
+	"default_kind.liquidity": "Liquidity shortage",
+
+	"step.initial":      "Initial state of the banking system",
+	"step.start_failed": "Stress test starts: banks %s are declared bankrupt",
+	"step.start_losses": "Stress test starts: banks receive initial losses",
+	"step.finished":     "Stress test finished",
+
+	"hint.next":    "Press Enter to continue",
+	"hint.exit":    "Press Esc to exit",
+	"window.title": "Banking system visualization",
+
+	"bank.balance_sheet": "Interbank: %.0f / %.0f\nExternal: %.0f\nCash: %.0f  Deposits: %.0f",
+
+	"report.title":     "Stress test",
+	"report.parameter": "Parameter",
+	"report.value":     "Value",
+	"report.defaults":  "Defaulted banks: %d of %d",
+	"report.total":     "Total",
+
+	"report.column.bank":            "Bank",
+	"report.column.initial_balance": "Initial balance",
+	"report.column.final_balance":   "Final balance",
+	"report.column.initial_loss":    "Initial shock",
+	"report.column.credit_loss":     "Credit shock",
+	"report.column.funding_loss":    "Funding shock",
+	"report.column.bank_run_loss":   "Bank run outflow",
+	"report.column.bank_run_gain":   "Bank run inflow",
+	"report.column.fire_sale_loss":  "Fire sales",
+	"report.column.total_loss":      "Net loss",
+	"report.column.default_round":   "Default round",
+	"report.column.bankrupt":        "Bankrupt",
+	"report.column.default_kind":    "Cause",
+	"report.column.liabilities":     "Liabilities",
+	"report.column.payment":         "Clearing payment",
+	"report.column.recovery_rate":   "Recovery rate",
+	"report.column.recovered":       "Received from debtors",
+
+	"report.bankrupt.true":  "Yes",
+	"report.bankrupt.false": "No",
+
+	"heatmap.defaults": "Number of defaults (%s)",
+	"heatmap.diff":     "Difference in defaults: full - ring",
+}
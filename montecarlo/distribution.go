@@ -0,0 +1,49 @@
+package montecarlo
+
+import "math"
+
+// Distribution распределение, заданное квантильной функцией. Квантильная форма позволяет
+// получать коррелированные выборки из общей гауссовой копулы
+type Distribution interface {
+	Quantile(u float64) float64
+}
+
+// Fixed вырожденное распределение, всегда возвращающее Value
+type Fixed struct {
+	Value float64
+}
+
+// Quantile возвращает Value
+func (d Fixed) Quantile(float64) float64 {
+	return d.Value
+}
+
+// Uniform равномерное распределение на отрезке [Min, Max]
+type Uniform struct {
+	Min, Max float64
+}
+
+// Quantile возвращает квантиль равномерного распределения
+func (d Uniform) Quantile(u float64) float64 {
+	return d.Min + u*(d.Max-d.Min)
+}
+
+// Normal нормальное распределение, обрезанное отрезком [0, 1], так как используется для долей потерь
+type Normal struct {
+	Mean, StdDev float64
+}
+
+// Quantile возвращает квантиль нормального распределения, обрезанный отрезком [0, 1]
+func (d Normal) Quantile(u float64) float64 {
+	return math.Max(0, math.Min(1, d.Mean+d.StdDev*normalQuantile(u)))
+}
+
+// normalCDF функция стандартного нормального распределения
+func normalCDF(x float64) float64 {
+	return 0.5 * math.Erfc(-x/math.Sqrt2)
+}
+
+// normalQuantile квантиль стандартного нормального распределения
+func normalQuantile(u float64) float64 {
+	return math.Sqrt2 * math.Erfinv(2*u-1)
+}
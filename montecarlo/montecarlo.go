@@ -0,0 +1,175 @@
+// Package montecarlo запускает стресс-тесты со случайными начальными шоками
+// и собирает распределение их последствий
+package montecarlo
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/nlypage/BankSystemVisualize/contagion"
+)
+
+// Config параметры прогона Монте-Карло
+type Config struct {
+	Runs int   // Количество прогонов
+	Seed int64 // Зерно генератора случайных чисел
+
+	// DefaultProbability вероятность того, что банк обанкротится в начальном шоке
+	DefaultProbability float64
+	// Loss распределение доли баланса, которую теряет не обанкротившийся банк (nil - без частичных потерь)
+	Loss Distribution
+	// Correlation корреляция начальных шоков между банками в однофакторной гауссовой модели, от 0 до 1
+	Correlation float64
+}
+
+// Result распределение последствий по всем прогонам
+type Result struct {
+	DefaultCounts []int     // Количество обанкротившихся банков в каждом прогоне
+	Losses        []float64 // Суммарные потери балансов в каждом прогоне
+
+	// DefaultProbability доля прогонов, в которых банк обанкротился
+	DefaultProbability map[string]float64
+}
+
+// validate проверяет параметры прогона
+func (cfg Config) validate() error {
+	if cfg.Runs < 1 {
+		return fmt.Errorf("количество прогонов должно быть положительным: %d", cfg.Runs)
+	}
+	if !unit(cfg.DefaultProbability) {
+		return fmt.Errorf("вероятность дефолта должна быть от 0 до 1: %g", cfg.DefaultProbability)
+	}
+	if !unit(cfg.Correlation) {
+		return fmt.Errorf("корреляция должна быть от 0 до 1: %g", cfg.Correlation)
+	}
+	switch loss := cfg.Loss.(type) {
+	case Fixed:
+		if !unit(loss.Value) {
+			return fmt.Errorf("доля потерь должна быть от 0 до 1: %g", loss.Value)
+		}
+	case Uniform:
+		if !unit(loss.Min) || !unit(loss.Max) || loss.Min > loss.Max {
+			return fmt.Errorf("границы доли потерь должны быть от 0 до 1 и минимум не больше максимума: %g, %g", loss.Min, loss.Max)
+		}
+	case Normal:
+		if !unit(loss.Mean) || !(loss.StdDev >= 0) || math.IsInf(loss.StdDev, 1) {
+			return fmt.Errorf("среднее доли потерь должно быть от 0 до 1, стандартное отклонение неотрицательным: %g, %g", loss.Mean, loss.StdDev)
+		}
+	}
+	return nil
+}
+
+// unit проверяет, что x лежит на отрезке [0, 1]. NaN не проходит проверку
+func unit(x float64) bool {
+	return x >= 0 && x <= 1
+}
+
+// Run запускает cfg.Runs стресс-тестов на копиях системы base.
+// Возвращает ошибку, если параметры cfg недопустимы
+func Run(base *contagion.BankSystem, cfg Config) (Result, error) {
+	if err := cfg.validate(); err != nil {
+		return Result{}, err
+	}
+
+	rng := rand.New(rand.NewSource(cfg.Seed))
+
+	names := make([]string, 0, len(base.Banks))
+	for name := range base.Banks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := Result{
+		DefaultCounts:      make([]int, 0, cfg.Runs),
+		Losses:             make([]float64, 0, cfg.Runs),
+		DefaultProbability: make(map[string]float64, len(names)),
+	}
+	for _, name := range names {
+		result.DefaultProbability[name] = 0
+	}
+	rho := cfg.Correlation
+
+	for run := 0; run < cfg.Runs; run++ {
+		system := base.Clone()
+
+		// Однофакторная модель: общий фактор рынка и собственный фактор каждого банка
+		market := rng.NormFloat64()
+		failed := make([]string, 0)
+		losses := make(map[string]float64)
+		for _, name := range names {
+			z := math.Sqrt(rho)*market + math.Sqrt(1-rho)*rng.NormFloat64()
+			u := normalCDF(z)
+			if u < cfg.DefaultProbability {
+				failed = append(failed, name)
+				continue
+			}
+			if cfg.Loss != nil {
+				// Чем хуже значение фактора, тем больше потери
+				share := math.Max(0, cfg.Loss.Quantile(1-u))
+				if loss := share * system.Banks[name].Balance; loss > 0 {
+					losses[name] = loss
+				}
+			}
+		}
+
+		result.DefaultCounts = append(result.DefaultCounts, system.Shock(failed, losses))
+		result.Losses = append(result.Losses, system.TotalLoss(base.Banks))
+		for _, name := range names {
+			if system.Banks[name].Bankrupt {
+				result.DefaultProbability[name]++
+			}
+		}
+	}
+
+	for name := range result.DefaultProbability {
+		result.DefaultProbability[name] /= float64(cfg.Runs)
+	}
+	return result, nil
+}
+
+// CountDistribution возвращает долю прогонов с каждым количеством банкротств
+func (r Result) CountDistribution() map[int]float64 {
+	distribution := make(map[int]float64)
+	for _, count := range r.DefaultCounts {
+		distribution[count] += 1 / float64(len(r.DefaultCounts))
+	}
+	return distribution
+}
+
+// MeanDefaults возвращает среднее количество банкротств
+func (r Result) MeanDefaults() float64 {
+	if len(r.DefaultCounts) == 0 {
+		return 0
+	}
+	total := 0
+	for _, count := range r.DefaultCounts {
+		total += count
+	}
+	return float64(total) / float64(len(r.DefaultCounts))
+}
+
+// MeanLoss возвращает средние суммарные потери
+func (r Result) MeanLoss() float64 {
+	if len(r.Losses) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, loss := range r.Losses {
+		total += loss
+	}
+	return total / float64(len(r.Losses))
+}
+
+// LossQuantile возвращает квантиль уровня q распределения суммарных потерь
+func (r Result) LossQuantile(q float64) float64 {
+	if len(r.Losses) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), r.Losses...)
+	sort.Float64s(sorted)
+	index := int(math.Ceil(q*float64(len(sorted)))) - 1
+	index = max(0, min(len(sorted)-1, index))
+	return sorted[index]
+}
@@ -0,0 +1,132 @@
+package montecarlo
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/nlypage/BankSystemVisualize/contagion"
+)
+
+// isolated возвращает систему из четырех банков без межбанковских связей: банкротства не распространяются,
+// поэтому число банкротств в прогоне равно числу начальных дефолтов
+func isolated() *contagion.BankSystem {
+	return &contagion.BankSystem{Banks: map[string]contagion.Bank{
+		"A": {Balance: 100},
+		"B": {Balance: 200},
+		"C": {Balance: 300},
+		"D": {Balance: 400},
+	}}
+}
+
+func TestRunIsReproducible(t *testing.T) {
+	cfg := Config{Runs: 200, Seed: 7, DefaultProbability: 0.2, Loss: Uniform{Min: 0, Max: 0.5}, Correlation: 0.3}
+	first, err := Run(isolated(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := Run(isolated(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Error("results differ for the same seed")
+	}
+
+	cfg.Seed = 8
+	third, err := Run(isolated(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reflect.DeepEqual(first.Losses, third.Losses) {
+		t.Error("results do not depend on the seed")
+	}
+}
+
+func TestRunDefaultProbability(t *testing.T) {
+	tests := []struct {
+		pd       float64
+		defaults int
+	}{
+		{pd: 0, defaults: 0},
+		{pd: 1, defaults: 4},
+	}
+	for _, tt := range tests {
+		result, err := Run(isolated(), Config{Runs: 50, Seed: 1, DefaultProbability: tt.pd, Correlation: 0.5})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for run, count := range result.DefaultCounts {
+			if count != tt.defaults {
+				t.Fatalf("pd = %g, run %d: %d defaults, want %d", tt.pd, run, count, tt.defaults)
+			}
+		}
+		for name, probability := range result.DefaultProbability {
+			if probability != tt.pd {
+				t.Errorf("pd = %g: default probability of %s = %g", tt.pd, name, probability)
+			}
+		}
+	}
+}
+
+func TestRunCorrelation(t *testing.T) {
+	// При полной корреляции все банки получают один и тот же фактор: либо банкротятся все, либо никто
+	full, err := Run(isolated(), Config{Runs: 500, Seed: 1, DefaultProbability: 0.3, Correlation: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for run, count := range full.DefaultCounts {
+		if count != 0 && count != 4 {
+			t.Fatalf("correlation 1, run %d: %d defaults", run, count)
+		}
+	}
+	if full.MeanDefaults() == 0 {
+		t.Error("correlation 1: no defaults at all")
+	}
+
+	// Без корреляции шоки независимы, и встречаются прогоны с частью банкротств
+	independent, err := Run(isolated(), Config{Runs: 500, Seed: 1, DefaultProbability: 0.3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	partial := false
+	for _, count := range independent.DefaultCounts {
+		partial = partial || (count > 0 && count < 4)
+	}
+	if !partial {
+		t.Error("correlation 0: every run has all or no defaults")
+	}
+	if mean := independent.MeanDefaults(); math.Abs(mean-1.2) > 0.2 {
+		t.Errorf("correlation 0: mean defaults = %g, want about 1.2", mean)
+	}
+}
+
+func TestRunValidation(t *testing.T) {
+	valid := Config{Runs: 10, DefaultProbability: 0.1, Loss: Uniform{Min: 0.1, Max: 0.5}, Correlation: 0.3}
+	tests := []struct {
+		name   string
+		modify func(cfg *Config)
+	}{
+		{"zero runs", func(cfg *Config) { cfg.Runs = 0 }},
+		{"negative runs", func(cfg *Config) { cfg.Runs = -1 }},
+		{"probability above 1", func(cfg *Config) { cfg.DefaultProbability = 3 }},
+		{"negative probability", func(cfg *Config) { cfg.DefaultProbability = -0.1 }},
+		{"NaN probability", func(cfg *Config) { cfg.DefaultProbability = math.NaN() }},
+		{"correlation above 1", func(cfg *Config) { cfg.Correlation = 1.5 }},
+		{"negative correlation", func(cfg *Config) { cfg.Correlation = -0.2 }},
+		{"min above max", func(cfg *Config) { cfg.Loss = Uniform{Min: 0.9, Max: 0.1} }},
+		{"max above 1", func(cfg *Config) { cfg.Loss = Uniform{Min: 0, Max: 2} }},
+		{"fixed loss above 1", func(cfg *Config) { cfg.Loss = Fixed{Value: 1.5} }},
+		{"negative deviation", func(cfg *Config) { cfg.Loss = Normal{Mean: 0.2, StdDev: -1} }},
+	}
+	if _, err := Run(isolated(), valid); err != nil {
+		t.Fatalf("valid config: %v", err)
+	}
+	for _, tt := range tests {
+		cfg := valid
+		tt.modify(&cfg)
+		if _, err := Run(isolated(), cfg); err == nil {
+			t.Errorf("%s: no error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+// Package plot рисует результаты перебора параметров в PNG без открытия окна
+package plot
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Отступы и размеры элементов диаграммы
+const (
+	marginLeft   = 70
+	marginRight  = 110
+	marginTop    = 40
+	marginBottom = 60
+	legendWidth  = 20
+	fontSize     = 13
+)
+
+// Heatmap фазовая диаграмма: значение метрики для каждой пары параметров
+type Heatmap struct {
+	Title  string
+	XLabel string
+	YLabel string
+	X      []float64   // Значения параметра по горизонтали
+	Y      []float64   // Значения параметра по вертикали
+	Values [][]float64 // Значения метрики, Values[строка по Y][столбец по X]
+}
+
+// newFace создает шрифт Go Regular, в нем есть и латиница, и кириллица
+func newFace(size float64) (font.Face, error) {
+	tt, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		return nil, err
+	}
+	return opentype.NewFace(tt, &opentype.FaceOptions{
+		Size:    size,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+}
+
+// WritePNG рисует диаграмму размером width x height и записывает ее в w в формате PNG
+func (h Heatmap) WritePNG(w io.Writer, width, height int) error {
+	img, err := h.Render(width, height)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// Render рисует диаграмму: клетки цветовой карты, подписи осей и легенду
+func (h Heatmap) Render(width, height int) (*image.RGBA, error) {
+	if len(h.X) == 0 || len(h.Y) == 0 || len(h.Values) != len(h.Y) {
+		return nil, fmt.Errorf("пустая или несогласованная сетка: %d x %d, строк значений %d", len(h.X), len(h.Y), len(h.Values))
+	}
+	face, err := newFace(fontSize)
+	if err != nil {
+		return nil, err
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	low, high := h.valueRange()
+	palette := sequential
+	if low < 0 {
+		// Для разностей используем расходящуюся шкалу с нулем посередине
+		palette = diverging
+		bound := math.Max(math.Abs(low), math.Abs(high))
+		low, high = -bound, bound
+	}
+
+	// Клетки диаграммы, Y растет снизу вверх
+	plotWidth := width - marginLeft - marginRight
+	plotHeight := height - marginTop - marginBottom
+	cellWidth := float64(plotWidth) / float64(len(h.X))
+	cellHeight := float64(plotHeight) / float64(len(h.Y))
+	for row := range h.Y {
+		if len(h.Values[row]) != len(h.X) {
+			return nil, fmt.Errorf("строка %d: ожидалось %d значений, получено %d", row, len(h.X), len(h.Values[row]))
+		}
+		for col := range h.X {
+			x0 := marginLeft + int(float64(col)*cellWidth)
+			x1 := marginLeft + int(float64(col+1)*cellWidth)
+			y0 := marginTop + plotHeight - int(float64(row+1)*cellHeight)
+			y1 := marginTop + plotHeight - int(float64(row)*cellHeight)
+			fill := palette(normalize(h.Values[row][col], low, high))
+			draw.Draw(img, image.Rect(x0, y0, x1, y1), image.NewUniform(fill), image.Point{}, draw.Src)
+		}
+	}
+
+	// Подписи делений осей, не чаще чем позволяет место
+	xEvery := labelStep(len(h.X), cellWidth, 40)
+	for col := 0; col < len(h.X); col += xEvery {
+		x := marginLeft + int((float64(col)+0.5)*cellWidth)
+		drawCentered(img, face, formatValue(h.X[col]), x, marginTop+plotHeight+18)
+	}
+	yEvery := labelStep(len(h.Y), cellHeight, 18)
+	for row := 0; row < len(h.Y); row += yEvery {
+		y := marginTop + plotHeight - int((float64(row)+0.5)*cellHeight)
+		drawString(img, face, formatValue(h.Y[row]), 10, y+5)
+	}
+
+	// Заголовок и названия осей
+	drawCentered(img, face, h.Title, marginLeft+plotWidth/2, marginTop-15)
+	drawCentered(img, face, h.XLabel, marginLeft+plotWidth/2, height-15)
+	drawString(img, face, h.YLabel, 10, marginTop-15)
+
+	// Легенда: вертикальная шкала цветов от минимума снизу к максимуму сверху
+	legendX := width - marginRight + 30
+	for y := 0; y < plotHeight; y++ {
+		fill := palette(1 - float64(y)/float64(plotHeight-1))
+		draw.Draw(img, image.Rect(legendX, marginTop+y, legendX+legendWidth, marginTop+y+1), image.NewUniform(fill), image.Point{}, draw.Src)
+	}
+	drawString(img, face, formatValue(high), legendX+legendWidth+5, marginTop+10)
+	drawString(img, face, formatValue((low+high)/2), legendX+legendWidth+5, marginTop+plotHeight/2+5)
+	drawString(img, face, formatValue(low), legendX+legendWidth+5, marginTop+plotHeight)
+
+	return img, nil
+}
+
+// valueRange возвращает минимальное и максимальное значение метрики
+func (h Heatmap) valueRange() (float64, float64) {
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, row := range h.Values {
+		for _, value := range row {
+			low = math.Min(low, value)
+			high = math.Max(high, value)
+		}
+	}
+	return low, high
+}
+
+// normalize переводит значение в долю отрезка [low, high]
+func normalize(value, low, high float64) float64 {
+	if high == low {
+		return 0.5
+	}
+	return (value - low) / (high - low)
+}
+
+// labelStep возвращает, через сколько делений ставить подпись, чтобы подписи не слипались
+func labelStep(count int, cellSize, minSpacing float64) int {
+	step := int(math.Ceil(minSpacing / cellSize))
+	return max(1, min(count, step))
+}
+
+// sequential шкала от белого к темно-красному
+func sequential(t float64) color.Color {
+	t = math.Max(0, math.Min(1, t))
+	return color.RGBA{
+		R: uint8(255 - 75*t),
+		G: uint8(255 - 235*t),
+		B: uint8(255 - 225*t),
+		A: 255,
+	}
+}
+
+// diverging шкала от синего через белый к красному
+func diverging(t float64) color.Color {
+	t = math.Max(0, math.Min(1, t))
+	if t < 0.5 {
+		s := (0.5 - t) * 2
+		return color.RGBA{R: uint8(255 - 215*s), G: uint8(255 - 155*s), B: uint8(255 - 55*s), A: 255}
+	}
+	s := (t - 0.5) * 2
+	return color.RGBA{R: uint8(255 - 75*s), G: uint8(255 - 235*s), B: uint8(255 - 225*s), A: 255}
+}
+
+// formatValue форматирует число для подписи
+func formatValue(value float64) string {
+	return fmt.Sprintf("%.3g", value)
+}
+
+// drawString рисует строку, (x, y) - начало базовой линии
+func drawString(img draw.Image, face font.Face, txt string, x, y int) {
+	drawer := font.Drawer{
+		Dst:  img,
+		Src:  image.Black,
+		Face: face,
+		Dot:  fixed.P(x, y),
+	}
+	drawer.DrawString(txt)
+}
+
+// drawCentered рисует строку с центром по горизонтали в точке x
+func drawCentered(img draw.Image, face font.Face, txt string, x, y int) {
+	width := font.MeasureString(face, txt).Ceil()
+	drawString(img, face, txt, x-width/2, y)
+}
@@ -0,0 +1,75 @@
+package plot
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  [][]float64
+		palette func(float64) color.Color
+		// Ожидаемые цвета центров клеток: нижней левой (строка 0, столбец 0) и верхней правой
+		bottomLeft, topRight float64
+	}{
+		{name: "sequential", values: [][]float64{{0, 1}, {2, 3}}, palette: sequential, bottomLeft: 0, topRight: 1},
+		// С отрицательными значениями шкала расходящаяся и симметрична относительно нуля
+		{name: "diverging", values: [][]float64{{0, -2}, {1, 2}}, palette: diverging, bottomLeft: 0.5, topRight: 1},
+	}
+	for _, tt := range tests {
+		h := Heatmap{Title: tt.name, XLabel: "lambda", YLabel: "p", X: []float64{0.1, 0.2}, Y: []float64{0.5, 1}, Values: tt.values}
+		img, err := h.Render(400, 300)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if size := img.Bounds().Size(); size.X != 400 || size.Y != 300 {
+			t.Errorf("%s: size = %v, want 400x300", tt.name, size)
+		}
+
+		// Поле диаграммы 220x200, клетки 110x100, Y растет снизу вверх
+		if got, want := img.RGBAAt(125, 190), tt.palette(tt.bottomLeft).(color.RGBA); got != want {
+			t.Errorf("%s: bottom left cell = %v, want %v", tt.name, got, want)
+		}
+		if got, want := img.RGBAAt(235, 90), tt.palette(tt.topRight).(color.RGBA); got != want {
+			t.Errorf("%s: top right cell = %v, want %v", tt.name, got, want)
+		}
+	}
+}
+
+func TestWritePNG(t *testing.T) {
+	h := Heatmap{X: []float64{1, 2, 3}, Y: []float64{1}, Values: [][]float64{{1, 2, 3}}}
+	var buf bytes.Buffer
+	if err := h.WritePNG(&buf, 320, 240); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := img.Bounds().Size(); size.X != 320 || size.Y != 240 {
+		t.Errorf("size = %v, want 320x240", size)
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		h    Heatmap
+	}{
+		{"no columns", Heatmap{Y: []float64{1}, Values: [][]float64{{}}}},
+		{"no rows", Heatmap{X: []float64{1}}},
+		{"missing row", Heatmap{X: []float64{1}, Y: []float64{1, 2}, Values: [][]float64{{1}}}},
+		{"short row", Heatmap{X: []float64{1, 2}, Y: []float64{1}, Values: [][]float64{{1}}}},
+	}
+	for _, tt := range tests {
+		if _, err := tt.h.Render(400, 300); err == nil {
+			t.Errorf("%s: no error", tt.name)
+		}
+		if err := tt.h.WritePNG(&bytes.Buffer{}, 400, 300); err == nil {
+			t.Errorf("%s: WritePNG returned no error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+// circleSegments количество отрезков, которыми приближается окружность
+const circleSegments = 64
+
+// point вершина многоугольника
+type point struct {
+	x, y float64
+}
+
+// fillPolygons закрашивает многоугольники с учетом направления обхода: контур, обойденный
+// в обратную сторону, вырезается из внешнего. Растеризатор создается только под ограничивающий
+// прямоугольник фигуры, чтобы не чистить весь кадр на каждую фигуру
+func fillPolygons(dst *image.RGBA, c color.Color, polygons ...[]point) {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, polygon := range polygons {
+		for _, p := range polygon {
+			minX, minY = math.Min(minX, p.x), math.Min(minY, p.y)
+			maxX, maxY = math.Max(maxX, p.x), math.Max(maxY, p.y)
+		}
+	}
+	bounds := image.Rect(int(math.Floor(minX)), int(math.Floor(minY)), int(math.Ceil(maxX)), int(math.Ceil(maxY))).
+		Intersect(dst.Bounds())
+	if bounds.Empty() {
+		return
+	}
+
+	// Растеризатор сам обрезает части контура, вышедшие за его границы
+	z := vector.NewRasterizer(bounds.Dx(), bounds.Dy())
+	ox, oy := float64(bounds.Min.X), float64(bounds.Min.Y)
+	for _, polygon := range polygons {
+		if len(polygon) == 0 {
+			continue
+		}
+		z.MoveTo(float32(polygon[0].x-ox), float32(polygon[0].y-oy))
+		for _, p := range polygon[1:] {
+			z.LineTo(float32(p.x-ox), float32(p.y-oy))
+		}
+		z.ClosePath()
+	}
+	z.Draw(dst, bounds, image.NewUniform(c), image.Point{})
+}
+
+// circle возвращает многоугольник окружности, reverse меняет направление обхода
+func circle(cx, cy, r float64, reverse bool) []point {
+	points := make([]point, circleSegments)
+	for i := range points {
+		angle := 2 * math.Pi * float64(i) / circleSegments
+		if reverse {
+			angle = -angle
+		}
+		points[i] = point{cx + r*math.Cos(angle), cy + r*math.Sin(angle)}
+	}
+	return points
+}
+
+// fillCircle рисует закрашенный круг
+func fillCircle(dst *image.RGBA, cx, cy, r float64, c color.Color) {
+	fillPolygons(dst, c, circle(cx, cy, r, false))
+}
+
+// strokeCircle рисует окружность толщиной width
+func strokeCircle(dst *image.RGBA, cx, cy, r, width float64, c color.Color) {
+	fillPolygons(dst, c, circle(cx, cy, r+width/2, false), circle(cx, cy, r-width/2, true))
+}
+
+// strokeLine рисует отрезок толщиной width
+func strokeLine(dst *image.RGBA, x1, y1, x2, y2, width float64, c color.Color) {
+	length := math.Hypot(x2-x1, y2-y1)
+	if length == 0 {
+		return
+	}
+	nx := -(y2 - y1) / length * width / 2
+	ny := (x2 - x1) / length * width / 2
+	fillPolygons(dst, c, []point{{x1 + nx, y1 + ny}, {x2 + nx, y2 + ny}, {x2 - nx, y2 - ny}, {x1 - nx, y1 - ny}})
+}
+
+// fillRect рисует закрашенный прямоугольник
+func fillRect(dst *image.RGBA, x, y, width, height float64, c color.Color) {
+	fillPolygons(dst, c, []point{{x, y}, {x + width, y}, {x + width, y + height}, {x, y + height}})
+}
+
+// drawText пишет текст, y - базовая линия первой строки. Перевод строки сдвигает текст
+// на высоту строки шрифта, как text.Draw в ebiten
+func drawText(dst *image.RGBA, face font.Face, str string, x, y int, c color.Color) {
+	drawer := font.Drawer{Dst: dst, Src: image.NewUniform(c), Face: face}
+	lineHeight := face.Metrics().Height
+	baseline := fixed.I(y)
+	for _, line := range strings.Split(str, "\n") {
+		drawer.Dot = fixed.Point26_6{X: fixed.I(x), Y: baseline}
+		drawer.DrawString(line)
+		baseline += lineHeight
+	}
+}
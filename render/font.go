@@ -0,0 +1,38 @@
+package render
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+)
+
+// DefaultFontSize размер шрифта по умолчанию
+const DefaultFontSize = 13
+
+// LoadFace загружает шрифт TTF или OTF из файла path. Пустой путь означает встроенный
+// шрифт Go Regular, в нем есть и латиница, и кириллица. Нулевой размер заменяется на DefaultFontSize
+func LoadFace(path string, size float64) (font.Face, error) {
+	data := goregular.TTF
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, err
+		}
+	}
+	if size <= 0 {
+		size = DefaultFontSize
+	}
+
+	tt, err := opentype.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return opentype.NewFace(tt, &opentype.FaceOptions{
+		Size:    size,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+}
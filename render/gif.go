@@ -0,0 +1,216 @@
+package render
+
+import (
+	"bufio"
+	"bytes"
+	"compress/lzw"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"io"
+)
+
+// gifPalette палитра GIF: куб 6×6×6 и 40 оттенков серого. Индекс цвета вычисляется
+// напрямую по его компонентам, без поиска ближайшего цвета палитры
+var gifPalette = func() color.Palette {
+	p := make(color.Palette, 0, 256)
+	for r := 0; r < 6; r++ {
+		for g := 0; g < 6; g++ {
+			for b := 0; b < 6; b++ {
+				p = append(p, color.RGBA{R: uint8(r * 51), G: uint8(g * 51), B: uint8(b * 51), A: 255})
+			}
+		}
+	}
+	for i := 0; i < 40; i++ {
+		v := uint8((i*255 + 19) / 39)
+		p = append(p, color.RGBA{R: v, G: v, B: v, A: 255})
+	}
+	return p
+}()
+
+// paletteIndex возвращает индекс цвета в gifPalette. Почти серые цвета (текст, сглаживание
+// на белом фоне) берутся из шкалы серого, остальные - из ближайшего узла куба
+func paletteIndex(r, g, b uint8) uint8 {
+	lo, hi := min(r, g, b), max(r, g, b)
+	if hi-lo < 8 {
+		sum := int(r) + int(g) + int(b)
+		return uint8(216 + (sum*39+3*255/2)/(3*255))
+	}
+	level := func(v uint8) int { return (int(v)*5 + 127) / 255 }
+	return uint8(level(r)*36 + level(g)*6 + level(b))
+}
+
+// maxGIFDelay наибольшая задержка кадра GIF в сотых долях секунды
+const maxGIFDelay = 0xFFFF
+
+// GIFRecorder записывает шаги стресс-теста в анимированный GIF. Кадры кодируются сразу
+// в W, в памяти хранится только последний кадр: пока картинка не меняется (частиц нет),
+// повторные кадры не записываются, а продлевают задержку предыдущего
+type GIFRecorder struct {
+	Scene *Scene
+	FPS   int     // Кадров в секунду, от 1 до 50 (меньшую задержку, чем 2/100 с, браузеры не соблюдают)
+	Dwell float64 // Сколько секунд показывается каждый шаг
+	W     io.Writer
+
+	out     *bufio.Writer
+	frame   *image.RGBA
+	pending *image.Paletted // Последний кадр, еще не записанный в W
+	next    *image.Paletted
+	delay   int // Задержка pending в сотых долях секунды
+	frames  int // Количество отрисованных кадров, по нему распределяются задержки
+	err     error
+}
+
+// Step записывает текущий шаг: Dwell секунд кадров, на которых летят частицы.
+// Подходит в качестве wait для Play
+func (r *GIFRecorder) Step() {
+	if r.err != nil {
+		return
+	}
+	if r.frame == nil {
+		r.start()
+	}
+
+	fps := min(max(r.FPS, 1), 50)
+	frames := max(int(r.Dwell*float64(fps)+0.5), 1)
+	for i := 0; i < frames; i++ {
+		r.Scene.Draw(r.frame)
+		quantize(r.next, r.frame)
+
+		// Задержка считается от общего времени, поэтому остаток от деления 100 на fps не теряется
+		delay := (r.frames+1)*100/fps - r.frames*100/fps
+		r.frames++
+
+		switch {
+		case r.delay == 0:
+			r.pending, r.next = r.next, r.pending
+			r.delay = delay
+		case r.delay+delay <= maxGIFDelay && bytes.Equal(r.next.Pix, r.pending.Pix):
+			r.delay += delay
+		default:
+			r.writeFrame()
+			r.pending, r.next = r.next, r.pending
+			r.delay = delay
+		}
+		r.Scene.Advance(1 / float64(fps))
+	}
+}
+
+// Close дописывает последний кадр и конец файла. W не закрывается
+func (r *GIFRecorder) Close() error {
+	if r.err != nil {
+		return r.err
+	}
+	if r.frame == nil {
+		r.start()
+	}
+	if r.delay > 0 {
+		r.writeFrame()
+	}
+	if r.err == nil {
+		r.err = r.out.WriteByte(0x3B)
+	}
+	if r.err == nil {
+		r.err = r.out.Flush()
+	}
+	return r.err
+}
+
+// start выделяет кадры и записывает заголовок GIF: размер, общую палитру и бесконечный повтор
+func (r *GIFRecorder) start() {
+	bounds := image.Rect(0, 0, Width, Height)
+	r.frame = image.NewRGBA(bounds)
+	r.pending = image.NewPaletted(bounds, gifPalette)
+	r.next = image.NewPaletted(bounds, gifPalette)
+	r.out = bufio.NewWriter(r.W)
+
+	header := []byte("GIF89a")
+	header = binary.LittleEndian.AppendUint16(header, Width)
+	header = binary.LittleEndian.AppendUint16(header, Height)
+	header = append(header, 0xF7, 0, 0) // Общая палитра из 256 цветов
+	for _, c := range gifPalette {
+		rgba := c.(color.RGBA)
+		header = append(header, rgba.R, rgba.G, rgba.B)
+	}
+	header = append(header, 0x21, 0xFF, 11)
+	header = append(header, "NETSCAPE2.0"...)
+	header = append(header, 3, 1, 0, 0, 0)
+	_, r.err = r.out.Write(header)
+}
+
+// writeFrame записывает кадр pending с задержкой delay
+func (r *GIFRecorder) writeFrame() {
+	if r.err != nil {
+		return
+	}
+	block := []byte{0x21, 0xF9, 4, 0}
+	block = binary.LittleEndian.AppendUint16(block, uint16(r.delay))
+	block = append(block, 0, 0, 0x2C, 0, 0, 0, 0)
+	block = binary.LittleEndian.AppendUint16(block, Width)
+	block = binary.LittleEndian.AppendUint16(block, Height)
+	block = append(block, 0, 8) // Без своей палитры, минимальный размер кода LZW
+	if _, r.err = r.out.Write(block); r.err != nil {
+		return
+	}
+
+	blocks := &subBlockWriter{w: r.out}
+	compressor := lzw.NewWriter(blocks, lzw.LSB, 8)
+	if _, r.err = compressor.Write(r.pending.Pix); r.err != nil {
+		return
+	}
+	if r.err = compressor.Close(); r.err != nil {
+		return
+	}
+	r.err = blocks.close()
+	r.delay = 0
+}
+
+// quantize переводит кадр в палитру gifPalette. Оба изображения начинаются в нуле и одного размера
+func quantize(dst *image.Paletted, src *image.RGBA) {
+	for i := range dst.Pix {
+		pix := src.Pix[i*4 : i*4+3]
+		dst.Pix[i] = paletteIndex(pix[0], pix[1], pix[2])
+	}
+}
+
+// subBlockWriter делит данные LZW на блоки GIF длиной до 255 байт
+type subBlockWriter struct {
+	w   *bufio.Writer
+	buf [256]byte // Первый байт - длина блока
+	n   int
+}
+
+func (b *subBlockWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		copied := copy(b.buf[1+b.n:], p)
+		b.n += copied
+		written += copied
+		p = p[copied:]
+		if b.n == 255 {
+			if err := b.flush(); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+// flush записывает накопленный блок
+func (b *subBlockWriter) flush() error {
+	if b.n == 0 {
+		return nil
+	}
+	b.buf[0] = byte(b.n)
+	_, err := b.w.Write(b.buf[:1+b.n])
+	b.n = 0
+	return err
+}
+
+// close записывает последний блок и пустой блок, завершающий данные кадра
+func (b *subBlockWriter) close() error {
+	if err := b.flush(); err != nil {
+		return err
+	}
+	return b.w.WriteByte(0)
+}
@@ -0,0 +1,109 @@
+package render
+
+import (
+	"bytes"
+	"image/gif"
+	"testing"
+
+	"github.com/nlypage/BankSystemVisualize/contagion"
+	"github.com/nlypage/BankSystemVisualize/topology"
+)
+
+// testScene возвращает сцену с кольцом из трех банков
+func testScene(t *testing.T) *Scene {
+	t.Helper()
+	face, err := LoadFace("", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	system := &contagion.BankSystem{Banks: topology.Ring(3, topology.Uniform(1000, 5000))}
+	for i, name := range []string{"1", "2", "3"} {
+		bank := system.Banks[name]
+		bank.X, bank.Y = float64(200+200*i), 400
+		system.Banks[name] = bank
+	}
+	return &Scene{System: system, Face: face}
+}
+
+func TestGIFRecorder(t *testing.T) {
+	tests := []struct {
+		name   string
+		fps    int
+		moving bool // Летит ли частица во время второго шага
+	}{
+		{name: "static steps", fps: 20},
+		{name: "static steps at 30 fps", fps: 30},
+		{name: "fps is clamped", fps: 1000},
+		{name: "moving particle", fps: 20, moving: true},
+		{name: "moving particle at 30 fps", fps: 30, moving: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scene := testScene(t)
+			var buf bytes.Buffer
+			recorder := &GIFRecorder{Scene: scene, FPS: tt.fps, Dwell: 1, W: &buf}
+			recorder.Step()
+			if tt.moving {
+				scene.Apply(contagion.Event{Type: contagion.EventFundingShock, Bank: "1", Counterparty: "2", Amount: 10})
+			}
+			recorder.Step()
+			if err := recorder.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			anim, err := gif.DecodeAll(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			total := 0
+			for _, delay := range anim.Delay {
+				total += delay
+			}
+			if total != 200 {
+				t.Errorf("total delay = %d, want 200 (two steps of one second)", total)
+			}
+			if !tt.moving {
+				if len(anim.Image) != 1 {
+					t.Errorf("got %d frames, want identical frames merged into one", len(anim.Image))
+				}
+			} else {
+				// Статичный первый шаг склеивается в один кадр, дальше кадр на каждое положение частицы
+				if anim.Delay[0] != 100 {
+					t.Errorf("first frame delay = %d, want 100", anim.Delay[0])
+				}
+				if len(anim.Image) < tt.fps/2 {
+					t.Errorf("got %d frames, want a frame per position of the particle", len(anim.Image))
+				}
+			}
+			for i, frame := range anim.Image {
+				if frame.Bounds().Dx() != Width || frame.Bounds().Dy() != Height {
+					t.Errorf("frame %d bounds = %v", i, frame.Bounds())
+				}
+			}
+		})
+	}
+}
+
+func TestPaletteIndex(t *testing.T) {
+	tests := []struct {
+		r, g, b uint8
+	}{
+		{255, 255, 255},
+		{0, 0, 0},
+		{128, 128, 128},
+		{0, 255, 0},
+		{0, 0, 139},
+		{160, 0, 220},
+		{255, 240, 240},
+		{255, 165, 55},
+	}
+	for _, tt := range tests {
+		c := gifPalette[paletteIndex(tt.r, tt.g, tt.b)]
+		r, g, b, _ := c.RGBA()
+		for i, pair := range [][2]uint8{{uint8(r >> 8), tt.r}, {uint8(g >> 8), tt.g}, {uint8(b >> 8), tt.b}} {
+			if diff := int(pair[0]) - int(pair[1]); diff > 26 || diff < -26 {
+				t.Errorf("color %v: channel %d = %d, too far from %d", tt, i, pair[0], pair[1])
+			}
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+)
+
+// PNGRecorder сохраняет каждый шаг стресс-теста отдельным PNG-файлом в каталог Dir.
+// Частицы текущего шага рисуются на середине пути, чтобы на кадре было видно движение средств
+type PNGRecorder struct {
+	Scene *Scene
+	Dir   string
+
+	step  int
+	frame *image.RGBA
+	err   error
+}
+
+// Step сохраняет текущий шаг в файл step_NNN.png. Подходит в качестве wait для Play
+func (r *PNGRecorder) Step() {
+	if r.err != nil {
+		return
+	}
+	if r.frame == nil {
+		if r.err = os.MkdirAll(r.Dir, 0o755); r.err != nil {
+			return
+		}
+		r.frame = image.NewRGBA(image.Rect(0, 0, Width, Height))
+	}
+
+	r.Scene.Advance(0.5 / particleSpeed)
+	r.Scene.Draw(r.frame)
+
+	path := filepath.Join(r.Dir, fmt.Sprintf("step_%03d.png", r.step))
+	r.step++
+	file, err := os.Create(path)
+	if err != nil {
+		r.err = err
+		return
+	}
+	if err := png.Encode(file, r.frame); err != nil {
+		file.Close()
+		r.err = fmt.Errorf("%s: %w", path, err)
+		return
+	}
+	r.err = file.Close()
+}
+
+// Err возвращает первую ошибку записи кадров
+func (r *PNGRecorder) Err() error {
+	return r.err
+}
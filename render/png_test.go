@@ -0,0 +1,59 @@
+package render
+
+import (
+	"fmt"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPNGRecorder(t *testing.T) {
+	const steps = 4
+	dir := filepath.Join(t.TempDir(), "frames")
+	recorder := &PNGRecorder{Scene: testScene(t), Dir: dir}
+	for i := 0; i < steps; i++ {
+		recorder.Step()
+	}
+	if err := recorder.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != steps {
+		t.Fatalf("got %d files, want %d", len(entries), steps)
+	}
+	for i, entry := range entries {
+		if want := fmt.Sprintf("step_%03d.png", i); entry.Name() != want {
+			t.Errorf("file %d = %s, want %s", i, entry.Name(), want)
+		}
+		file, err := os.Open(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		config, err := png.DecodeConfig(file)
+		file.Close()
+		if err != nil {
+			t.Errorf("%s: %v", entry.Name(), err)
+		} else if config.Width != Width || config.Height != Height {
+			t.Errorf("%s: size %dx%d, want %dx%d", entry.Name(), config.Width, config.Height, Width, Height)
+		}
+	}
+}
+
+func TestPNGRecorderError(t *testing.T) {
+	// Каталог нельзя создать: на его месте уже лежит файл
+	path := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	recorder := &PNGRecorder{Scene: testScene(t), Dir: path}
+	recorder.Step()
+	recorder.Step()
+	if recorder.Err() == nil {
+		t.Error("no error when the directory cannot be created")
+	}
+}
@@ -0,0 +1,393 @@
+// Package render рисует банковскую систему без окна: кадры для визуализатора, GIF и PNG
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/font"
+
+	"github.com/nlypage/BankSystemVisualize/contagion"
+	"github.com/nlypage/BankSystemVisualize/locale"
+)
+
+// Константы для настройки визуализации
+const (
+	Width           = 800
+	Height          = 800
+	bankRadius      = 50
+	particleSpeed   = 0.6 // Доля пути, которую частица проходит за секунду
+	arrowThickness  = 5.0
+	transactionSize = 8
+)
+
+// Переменные для настройки цветов
+var (
+	arrowColor       = color.Black
+	textColor        = color.RGBA{B: 139, A: 255}
+	transactionColor = color.RGBA{G: 255, A: 255}
+	fireSaleColor    = color.RGBA{R: 160, B: 220, A: 255}
+)
+
+// Transaction представляет анимацию перевода средств между банками
+type Transaction struct {
+	FromX, FromY float64
+	ToX, ToY     float64
+	Amount       float64
+	Progress     float64
+	Color        color.RGBA
+}
+
+// Scene состояние экрана визуализатора: система, сообщения и летящие частицы.
+// Draw и Advance можно вызывать из другой горутины, пока идет Play: стресс-тест меняет систему
+// и сцену только под блокировкой, которую Play отпускает на время ожидания шага
+type Scene struct {
+	System        *contagion.BankSystem
+	Face          font.Face
+	Message       string
+	Hint          string // Подсказка под сообщением (пустая при записи без окна)
+	Footer        string // Подсказка внизу экрана
+	StaticMessage string // Параметры модели в правом верхнем углу
+	Transactions  []Transaction
+	Importance    map[string]int  // Место банка в рейтинге системной значимости (nil - без раскраски)
+	Messages      *locale.Catalog // Каталог сообщений (nil - русский)
+
+	mu sync.Mutex
+}
+
+// Apply показывает событие каскада: запускает частицу и меняет сообщение
+func (sc *Scene) Apply(e contagion.Event) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.apply(e)
+}
+
+// apply реализация Apply, вызывается под блокировкой
+func (sc *Scene) apply(e contagion.Event) {
+	if e.Counterparty != "" {
+		from, to := sc.System.Banks[e.Bank], sc.System.Banks[e.Counterparty]
+		sc.Transactions = append(sc.Transactions, Transaction{
+			FromX:    from.X,
+			FromY:    from.Y,
+			ToX:      to.X,
+			ToY:      to.Y,
+			Amount:   e.Amount,
+			Progress: 0,
+			Color:    EventColor(e),
+		})
+	}
+	sc.Message = EventMessage(sc.Messages, e)
+}
+
+// Advance сдвигает частицы на dt секунд и убирает долетевшие
+func (sc *Scene) Advance(dt float64) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	for i := len(sc.Transactions) - 1; i >= 0; i-- {
+		t := &sc.Transactions[i]
+		t.Progress += particleSpeed * dt
+		if t.Progress >= 1.0 {
+			sc.Transactions = append(sc.Transactions[:i], sc.Transactions[i+1:]...)
+		}
+	}
+}
+
+// Draw рисует сцену в dst
+func (sc *Scene) Draw(dst *image.RGBA) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+
+	// Отображаем текст
+	sc.drawText(dst, sc.Message, 10, 20)
+	sc.drawText(dst, sc.Hint, 10, 40)
+	sc.drawText(dst, sc.Footer, 10, Height-10)
+	sc.drawText(dst, sc.StaticMessage, Width-80, 20)
+
+	// Рисуем стрелки
+	for _, name := range sortedNames(sc.System.Banks) {
+		bank := sc.System.Banks[name]
+		for _, debtor := range sortedNames(bank.Dependencies) {
+			debtorBank := sc.System.Banks[debtor]
+			sc.drawArrow(dst, name, debtor, bank.X, bank.Y, debtorBank.X, debtorBank.Y, bank.Dependencies[debtor])
+		}
+	}
+
+	// Рисуем анимации транзакций
+	for _, t := range sc.Transactions {
+		currentX := t.FromX + (t.ToX-t.FromX)*t.Progress
+		currentY := t.FromY + (t.ToY-t.FromY)*t.Progress
+		fillCircle(dst, currentX, currentY, transactionSize, t.Color)
+	}
+
+	// Рисуем банки поверх всего
+	for _, name := range sortedNames(sc.System.Banks) {
+		sc.drawBank(dst, name, sc.System.Banks[name])
+	}
+}
+
+// drawText пишет текст черным цветом
+func (sc *Scene) drawText(dst *image.RGBA, str string, x, y int) {
+	if str != "" {
+		drawText(dst, sc.Face, str, x, y, color.Black)
+	}
+}
+
+// drawBank рисует банк
+func (sc *Scene) drawBank(dst *image.RGBA, name string, bank contagion.Bank) {
+	// Рисуем тень
+	fillCircle(dst, bank.X+4, bank.Y+4, bankRadius, color.RGBA{A: 40})
+
+	// Определяем цвета для банка
+	var bankFillColor, bankStrokeColor color.Color
+	if bank.Bankrupt {
+		// Для банкрота - бледно-красный фон и темно-красная обводка
+		bankFillColor = color.RGBA{R: 255, G: 240, B: 240, A: 255}
+		bankStrokeColor = color.RGBA{R: 180, A: 255}
+	} else if bank.Distress > 0 {
+		// Для банка в дистрессе - фон темнеет с ростом дистресса, оранжевая обводка
+		shade := uint8(255 - 80*bank.Distress)
+		bankFillColor = color.RGBA{R: 255, G: shade, B: shade, A: 255}
+		bankStrokeColor = color.RGBA{R: 220, G: 120, A: 255}
+	} else if rank, exists := sc.Importance[name]; exists {
+		// Раскраска по системной значимости - чем выше место в рейтинге, тем насыщеннее фон
+		intensity := 1.0
+		if len(sc.Importance) > 1 {
+			intensity = 1 - float64(rank-1)/float64(len(sc.Importance)-1)
+		}
+		bankFillColor = color.RGBA{R: 255, G: uint8(255 - 90*intensity), B: uint8(255 - 200*intensity), A: 255}
+		bankStrokeColor = color.RGBA{G: 180, A: 255}
+	} else {
+		// Для активного банка - белый фон и темно-зеленая обводка
+		bankFillColor = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+		bankStrokeColor = color.RGBA{G: 180, A: 255}
+	}
+
+	// Рисуем основной круг банка и двойную обводку для эффекта глубины
+	fillCircle(dst, bank.X, bank.Y, bankRadius, bankFillColor)
+	strokeCircle(dst, bank.X, bank.Y, bankRadius, 3, bankStrokeColor)
+	strokeCircle(dst, bank.X, bank.Y, bankRadius-1, 1, bankStrokeColor)
+
+	// Рисуем текст
+	title := name
+	if bank.Label != "" {
+		title = bank.Label
+	}
+	txt := fmt.Sprintf("%s\n%.1f", title, bank.Balance)
+	if bank.Distress > 0 {
+		txt += fmt.Sprintf("\nh = %.2f", bank.Distress)
+	}
+	if rank, exists := sc.Importance[name]; exists {
+		txt += fmt.Sprintf("\n#%d", rank)
+	}
+	sc.drawText(dst, txt, int(bank.X)-15, int(bank.Y))
+
+	// Рисуем структуру баланса под банком
+	sheet := sc.System.BalanceSheet(name)
+	breakdown := sc.Messages.T("bank.balance_sheet",
+		sheet.InterbankAssets, sheet.InterbankLiabilities, sheet.ExternalAssets, sheet.Cash, sheet.Deposits)
+	if bank.Bankrupt {
+		breakdown += "\n" + DefaultKindLabel(sc.Messages, bank.DefaultKind)
+	}
+	sc.drawText(dst, breakdown, int(bank.X)-bankRadius, int(bank.Y)+bankRadius+15)
+}
+
+// drawArrow рисует требование банка creditor к банку debtor
+func (sc *Scene) drawArrow(dst *image.RGBA, creditor, debtor string, x1, y1, x2, y2, amount float64) {
+	dx := x2 - x1
+	dy := y2 - y1
+	length := math.Sqrt(dx*dx + dy*dy)
+
+	dx /= length
+	dy /= length
+
+	// Проверяем, есть ли обратная зависимость
+	bank1to2 := amount
+	bank2to1 := sc.System.Banks[debtor].Dependencies[creditor]
+	label1to2 := sc.edgeLabel(creditor, debtor, bank1to2)
+	label2to1 := sc.edgeLabel(debtor, creditor, bank2to1)
+
+	// Если есть зависимость в обе стороны
+	if bank2to1 > 0 {
+		if bank1to2 == bank2to1 && label1to2 == label2to1 {
+			// Если суммы равны, рисуем одну стрелку с двумя наконечниками
+			startX := x1 + dx*bankRadius
+			startY := y1 + dy*bankRadius
+			endX := x2 - dx*bankRadius
+			endY := y2 - dy*bankRadius
+
+			strokeLine(dst, startX, startY, endX, endY, arrowThickness, arrowColor)
+			drawArrowHead(dst, endX, endY, dx, dy, arrowColor)
+			drawArrowHead(dst, startX, startY, -dx, -dy, arrowColor)
+
+			// Подпись значения с фоном
+			sc.drawTextWithBackground(dst, label1to2, int((startX+endX)/2), int((startY+endY)/2), textColor)
+		} else {
+			// Если суммы разные, рисуем две параллельные линии
+			offset := float64(15)
+			normalX := -dy * offset
+			normalY := dx * offset
+
+			// Первая линия
+			startX1 := x1 + dx*bankRadius + normalX
+			startY1 := y1 + dy*bankRadius + normalY
+			endX1 := x2 - dx*bankRadius + normalX
+			endY1 := y2 - dy*bankRadius + normalY
+
+			// Вторая линия
+			startX2 := x1 + dx*bankRadius - normalX
+			startY2 := y1 + dy*bankRadius - normalY
+			endX2 := x2 - dx*bankRadius - normalX
+			endY2 := y2 - dy*bankRadius - normalY
+
+			strokeLine(dst, startX1, startY1, endX1, endY1, arrowThickness, arrowColor)
+			strokeLine(dst, startX2, startY2, endX2, endY2, arrowThickness, arrowColor)
+			drawArrowHead(dst, endX1, endY1, dx, dy, arrowColor)
+			drawArrowHead(dst, endX2, endY2, -dx, -dy, arrowColor)
+
+			// Подписи значений с фоном
+			sc.drawTextWithBackground(dst, label1to2, int((startX1+endX1)/2), int((startY1+endY1)/2), textColor)
+			sc.drawTextWithBackground(dst, label2to1, int((startX2+endX2)/2), int((startY2+endY2)/2), textColor)
+		}
+	} else {
+		// Обычная однонаправленная стрелка
+		startX := x1 + dx*bankRadius
+		startY := y1 + dy*bankRadius
+		endX := x2 - dx*bankRadius
+		endY := y2 - dy*bankRadius
+
+		strokeLine(dst, startX, startY, endX, endY, arrowThickness, arrowColor)
+		drawArrowHead(dst, endX, endY, dx, dy, arrowColor)
+
+		// Подпись значения с фоном
+		sc.drawTextWithBackground(dst, label1to2, int((startX+endX)/2), int((startY+endY)/2), textColor)
+	}
+}
+
+// edgeLabel формирует подпись требования и добавляет доли потерь, если они переопределены для этого требования
+func (sc *Scene) edgeLabel(creditor, debtor string, amount float64) string {
+	txt := fmt.Sprintf("%.1f", amount)
+	bank := sc.System.Banks[creditor]
+	if rate, exists := bank.CreditLoss[debtor]; exists {
+		txt += fmt.Sprintf(" λc=%.2f", rate)
+	}
+	if rate, exists := bank.FundingLoss[debtor]; exists {
+		txt += fmt.Sprintf(" λf=%.2f", rate)
+	}
+	return txt
+}
+
+// drawArrowHead рисует наконечник стрелки
+func drawArrowHead(dst *image.RGBA, x, y, dx, dy float64, c color.Color) {
+	arrowSize := float64(12)
+	angle := math.Pi / 4
+
+	angle1 := math.Atan2(dy, dx) + angle
+	angle2 := math.Atan2(dy, dx) - angle
+
+	strokeLine(dst, x, y, x-arrowSize*math.Cos(angle1), y-arrowSize*math.Sin(angle1), arrowThickness/2, c)
+	strokeLine(dst, x, y, x-arrowSize*math.Cos(angle2), y-arrowSize*math.Sin(angle2), arrowThickness/2, c)
+}
+
+// drawTextWithBackground пишет текст на полупрозрачном белом фоне
+func (sc *Scene) drawTextWithBackground(dst *image.RGBA, txt string, x, y int, c color.Color) {
+	padding := 4
+	width := len(txt)*7 + padding*2
+	height := 15 + padding*2
+
+	fillRect(dst, float64(x-width/2), float64(y-height/2), float64(width), float64(height), color.NRGBA{R: 255, G: 255, B: 255, A: 220})
+	drawText(dst, sc.Face, txt, x-width/2+padding, y+height/3, c)
+}
+
+// EventColor возвращает цвет частицы для события: у каждого канала потерь свой цвет
+func EventColor(e contagion.Event) color.RGBA {
+	if e.Type == contagion.EventFireSale {
+		return fireSaleColor
+	}
+	return transactionColor
+}
+
+// EventMessage формирует текст сообщения для события каскада
+func EventMessage(messages *locale.Catalog, e contagion.Event) string {
+	switch e.Type {
+	case contagion.EventDefault:
+		return messages.T("event.default", e.Bank, DefaultKindLabel(messages, e.DefaultKind))
+	case contagion.EventFundingShock:
+		return messages.T("event.funding_shock", e.Cause, e.Bank, e.Amount)
+	case contagion.EventCreditShock:
+		return messages.T("event.credit_shock", e.Cause, e.Bank, e.Amount)
+	case contagion.EventBankRun:
+		return messages.T("event.bank_run", e.Counterparty, e.Amount, e.Bank, e.Cause)
+	case contagion.EventFireSale:
+		return messages.T("event.fire_sale", e.Cause, e.Bank, e.Amount)
+	case contagion.EventRecovery:
+		return messages.T("event.recovery", e.Bank, e.Counterparty, e.Amount)
+	case contagion.EventInitialLoss:
+		return messages.T("event.initial_loss", e.Bank, e.Amount)
+	case contagion.EventDistress:
+		if e.Cause == "" {
+			return messages.T("event.distress_initial", e.Bank, e.Amount)
+		}
+		return messages.T("event.distress", e.Bank, e.Amount, e.Cause)
+	default:
+		return ""
+	}
+}
+
+// DefaultKindLabel возвращает подпись причины банкротства
+func DefaultKindLabel(messages *locale.Catalog, kind contagion.DefaultKind) string {
+	switch kind {
+	case contagion.InitialDefault, contagion.SolvencyDefault, contagion.LiquidityDefault:
+		return messages.T("default_kind." + kind.String())
+	default:
+		return ""
+	}
+}
+
+// Play проводит стресс-тест по шагам: банки failed объявляются банкротами, остальные теряют
+// losses[name] из баланса. wait вызывается после каждого шага - начального состояния, начала
+// стресс-теста, каждого события и завершения - и решает, сколько показывать шаг.
+// Система и сцена меняются под блокировкой сцены, на время wait она отпускается
+func Play(sc *Scene, failed []string, losses map[string]float64, wait func()) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	step := func() {
+		sc.mu.Unlock()
+		defer sc.mu.Lock()
+		wait()
+	}
+
+	sc.Message = sc.Messages.T("step.initial")
+	step()
+	if len(failed) > 0 {
+		sc.Message = sc.Messages.T("step.start_failed", strings.Join(failed, ", "))
+	} else {
+		sc.Message = sc.Messages.T("step.start_losses")
+	}
+	step()
+
+	sc.System.Subscribe(contagion.ObserverFunc(func(e contagion.Event) {
+		sc.apply(e)
+		step()
+	}))
+	sc.System.Shock(failed, losses)
+
+	sc.Message = sc.Messages.T("step.finished")
+	step()
+}
+
+// sortedNames возвращает ключи словаря по алфавиту, чтобы кадры рисовались одинаково
+func sortedNames[V any](m map[string]V) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
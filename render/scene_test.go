@@ -0,0 +1,50 @@
+package render
+
+import (
+	"image"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Окно рисует сцену в своей горутине, пока стресс-тест идет в другой. С -race тест проверяет,
+// что Draw и Advance не читают систему и частицы одновременно с их изменением. Пауза в wait
+// дает окну нарисовать несколько кадров на каждом шаге
+func TestPlayConcurrentDraw(t *testing.T) {
+	scene := testScene(t)
+	scene.System.LambdaC, scene.System.LambdaF = 0.5, 0.5
+	scene.System.EnablePanic, scene.System.PanicRate = true, 0.7
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		frame := image.NewRGBA(image.Rect(0, 0, Width, Height))
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				scene.Draw(frame)
+				scene.Advance(0.01)
+			}
+		}
+	}()
+
+	steps := 0
+	Play(scene, []string{"1"}, nil, func() {
+		steps++
+		time.Sleep(time.Millisecond)
+	})
+	close(done)
+	wg.Wait()
+
+	// Начальное состояние, начало, события каскада и завершение
+	if steps < 4 {
+		t.Errorf("Play made %d steps", steps)
+	}
+	if !scene.System.Banks["1"].Bankrupt {
+		t.Error("bank 1 is not bankrupt after Play")
+	}
+}
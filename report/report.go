@@ -0,0 +1,201 @@
+// Package report собирает итоги стресс-теста по банкам и записывает их в CSV, Markdown и HTML
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nlypage/BankSystemVisualize/contagion"
+	"github.com/nlypage/BankSystemVisualize/locale"
+)
+
+// Parameter параметр прогона, который печатается в шапке отчета
+type Parameter struct {
+	Name  string
+	Value string
+}
+
+// BankRow итоги стресс-теста для одного банка
+type BankRow struct {
+	Bank           string
+	Label          string
+	InitialBalance float64
+	FinalBalance   float64
+	InitialLoss    float64 // Частичные потери в начальном шоке
+	CreditLoss     float64 // Потери от кредитных шоков, в DebtRank - от дистресса должников
+	FundingLoss    float64 // Потери от шоков фондирования
+	BankRunLoss    float64 // Вклады, забранные из банка при набеге
+	BankRunGain    float64 // Вклады, которые банк забрал из других банков при набеге
+	FireSaleLoss   float64 // Потери на переоценке при вынужденных продажах
+	Bankrupt       bool
+	DefaultKind    contagion.DefaultKind
+	DefaultRound   int       // Уровень каскада, на котором банк обанкротился, -1 если не обанкротился
+	Clearing       *Clearing // Итоги клиринга Айзенберга–Ноэ (nil для других моделей)
+}
+
+// Clearing итоги клиринга Айзенберга–Ноэ для одного банка
+type Clearing struct {
+	Liabilities  float64 // Номинальные межбанковские обязательства
+	Payment      float64 // Клиринговый платеж банка кредиторам
+	RecoveryRate float64 // Доля обязательств банка, которую получили его кредиторы
+	Recovered    float64 // Сколько банк получил от своих должников
+}
+
+// Loss возвращает чистые потери банка: потери по всем каналам за вычетом вкладов, полученных при набеге.
+// Для банков, не обанкротившихся в начальном шоке, равны снижению баланса
+func (r BankRow) Loss() float64 {
+	return r.InitialLoss + r.CreditLoss + r.FundingLoss + r.BankRunLoss - r.BankRunGain + r.FireSaleLoss
+}
+
+// Report итоги стресс-теста
+type Report struct {
+	Title      string
+	Parameters []Parameter
+	Banks      []BankRow       // Отсортированы по имени банка
+	Messages   *locale.Catalog // Каталог подписей Markdown и HTML (nil - русский)
+}
+
+// Totals возвращает строку с суммами по системе: балансы и потери складываются,
+// Bankrupt не используется, DefaultRound - число раундов каскада, RecoveryRate - доля всех
+// межбанковских обязательств, выплаченная при клиринге
+func (r *Report) Totals() BankRow {
+	totals := BankRow{DefaultRound: -1}
+	if r.hasClearing() {
+		totals.Clearing = &Clearing{}
+	}
+	for _, row := range r.Banks {
+		totals.InitialBalance += row.InitialBalance
+		totals.FinalBalance += row.FinalBalance
+		totals.InitialLoss += row.InitialLoss
+		totals.CreditLoss += row.CreditLoss
+		totals.FundingLoss += row.FundingLoss
+		totals.BankRunLoss += row.BankRunLoss
+		totals.BankRunGain += row.BankRunGain
+		totals.FireSaleLoss += row.FireSaleLoss
+		totals.DefaultRound = max(totals.DefaultRound, row.DefaultRound)
+		if row.Clearing != nil && totals.Clearing != nil {
+			totals.Clearing.Liabilities += row.Clearing.Liabilities
+			totals.Clearing.Payment += row.Clearing.Payment
+			totals.Clearing.Recovered += row.Clearing.Recovered
+		}
+	}
+	if totals.Clearing != nil {
+		totals.Clearing.RecoveryRate = 1
+		if totals.Clearing.Liabilities > 0 {
+			totals.Clearing.RecoveryRate = totals.Clearing.Payment / totals.Clearing.Liabilities
+		}
+	}
+	return totals
+}
+
+// hasClearing сообщает, есть ли в отчете итоги клиринга Айзенберга–Ноэ
+func (r *Report) hasClearing() bool {
+	return len(r.Banks) > 0 && r.Banks[0].Clearing != nil
+}
+
+// Defaults возвращает количество обанкротившихся банков
+func (r *Report) Defaults() int {
+	defaults := 0
+	for _, row := range r.Banks {
+		if row.Bankrupt {
+			defaults++
+		}
+	}
+	return defaults
+}
+
+// Collector наблюдатель, который копит потери по каналам во время стресс-теста
+type Collector struct {
+	system  *contagion.BankSystem
+	initial map[string]contagion.Bank
+	rows    map[string]*BankRow
+}
+
+// NewCollector создает сборщик для системы. Вызывается до запуска стресс-теста, чтобы запомнить исходные балансы
+func NewCollector(system *contagion.BankSystem) *Collector {
+	rows := make(map[string]*BankRow, len(system.Banks))
+	for name := range system.Banks {
+		rows[name] = &BankRow{Bank: name, DefaultRound: -1}
+	}
+	return &Collector{system: system, initial: contagion.CloneBanks(system.Banks), rows: rows}
+}
+
+// OnEvent учитывает событие в потерях банка. Набег при учете ликвидности меняет только денежные
+// средства, а не капитал, поэтому в потерях не учитывается
+func (c *Collector) OnEvent(e contagion.Event) {
+	row := c.rows[e.Bank]
+	switch e.Type {
+	case contagion.EventInitialLoss:
+		row.InitialLoss += e.Amount
+	case contagion.EventCreditShock:
+		row.CreditLoss += e.Amount
+	case contagion.EventFundingShock:
+		row.FundingLoss += e.Amount
+	case contagion.EventBankRun:
+		if !c.system.EnableLiquidity {
+			row.BankRunLoss += e.Amount
+			c.rows[e.Counterparty].BankRunGain += e.Amount
+		}
+	case contagion.EventFireSale:
+		row.FireSaleLoss += e.Amount
+	case contagion.EventDefault:
+		row.DefaultRound = e.Level
+	case contagion.EventDistress:
+		// Дистресс от должников - доля исходного капитала, потерянная по требованиям к ним
+		if e.Cause != "" {
+			row.CreditLoss += e.Amount * max(0, c.initial[e.Bank].Balance)
+		}
+	}
+}
+
+// Report собирает отчет по текущему состоянию системы
+func (c *Collector) Report(title string) *Report {
+	s := c.system
+	report := &Report{
+		Title: title,
+		Parameters: []Parameter{
+			{"model", s.Model.String()},
+			{"lambda_c", formatFloat(s.LambdaC)},
+			{"lambda_f", formatFloat(s.LambdaF)},
+			{"p", formatFloat(s.PanicRate)},
+			{"panic", fmt.Sprint(s.EnablePanic)},
+			{"liquidity", fmt.Sprint(s.EnableLiquidity)},
+			{"capital_ratio", formatFloat(s.CapitalRatio)},
+			{"order", s.Order.String()},
+			{"update", s.Update.String()},
+		},
+	}
+
+	names := make([]string, 0, len(c.rows))
+	for name := range c.rows {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		row := *c.rows[name]
+		bank := s.Banks[name]
+		row.Label = bank.Label
+		row.InitialBalance = c.initial[name].Balance
+		row.FinalBalance = bank.Balance
+		row.Bankrupt = bank.Bankrupt
+		row.DefaultKind = bank.DefaultKind
+		if clearing := s.Clearing; clearing != nil {
+			row.Clearing = &Clearing{
+				Liabilities:  clearing.Liabilities[name],
+				Payment:      clearing.Payments[name],
+				RecoveryRate: clearing.RecoveryRate(name),
+			}
+			for _, amount := range clearing.Recovery[name] {
+				row.Clearing.Recovered += amount
+			}
+		}
+		report.Banks = append(report.Banks, row)
+	}
+	return report
+}
+
+// formatFloat печатает число без лишних нулей
+func formatFloat(v float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.4f", v), "0"), ".")
+}
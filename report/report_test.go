@@ -0,0 +1,55 @@
+package report
+
+import (
+	"math"
+	"testing"
+
+	"github.com/nlypage/BankSystemVisualize/contagion"
+	"github.com/nlypage/BankSystemVisualize/topology"
+)
+
+// Чистые потери каждого банка, кроме банкротов начального шока, равны снижению его баланса:
+// отток при набеге уравновешивается притоком у вкладчика, а при учете ликвидности набег капитал не затрагивает
+func TestCollectorLossMatchesBalance(t *testing.T) {
+	tests := []struct {
+		name   string
+		system *contagion.BankSystem
+		losses map[string]float64 // Частичные потери вместо банкротства банка 1
+	}{
+		{"full", &contagion.BankSystem{LambdaC: 0.5, LambdaF: 0.5, EnablePanic: true, PanicRate: 0.6,
+			Banks: topology.Complete(5, topology.Uniform(1000, 5000))}, nil},
+		{"ring synchronous", &contagion.BankSystem{LambdaC: 0.5, LambdaF: 0.5, EnablePanic: true, PanicRate: 0.6,
+			Update: contagion.UpdateSynchronous, Banks: topology.Ring(5, topology.Uniform(1000, 5000))}, nil},
+		{"full liquidity", &contagion.BankSystem{LambdaC: 0.5, LambdaF: 0.5, EnablePanic: true, PanicRate: 0.6, EnableLiquidity: true,
+			Banks: topology.Complete(5, topology.Uniform(1000, 5000))}, nil},
+		{"ring losses", &contagion.BankSystem{LambdaC: 0.5, LambdaF: 0.5, EnablePanic: true, PanicRate: 0.6,
+			Banks: topology.Ring(5, topology.Uniform(1000, 5000))}, map[string]float64{"1": 1500, "2": 200}},
+	}
+	for _, tt := range tests {
+		collector := NewCollector(tt.system)
+		tt.system.Subscribe(collector)
+		if tt.losses != nil {
+			tt.system.Shock(nil, tt.losses)
+		} else {
+			tt.system.Shock([]string{"1"}, nil)
+		}
+
+		r := collector.Report(tt.name)
+		gains := 0.0
+		for _, row := range r.Banks {
+			gains += row.BankRunGain
+			if row.DefaultKind == contagion.InitialDefault {
+				continue
+			}
+			if drop := row.InitialBalance - row.FinalBalance; math.Abs(row.Loss()-drop) > 1e-6 {
+				t.Errorf("%s: bank %s loss = %.2f, balance drop = %.2f", tt.name, row.Bank, row.Loss(), drop)
+			}
+		}
+		if tt.system.EnableLiquidity && gains != 0 {
+			t.Errorf("%s: bank run inflows %.2f in the liquidity mode", tt.name, gains)
+		}
+		if !tt.system.EnableLiquidity && gains == 0 {
+			t.Errorf("%s: no bank run inflows", tt.name)
+		}
+	}
+}
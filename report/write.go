@@ -0,0 +1,253 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/nlypage/BankSystemVisualize/contagion"
+)
+
+// Столбцы таблицы банков: имена в CSV, подписи для Markdown и HTML берутся из каталога сообщений
+var bankColumns = []string{
+	"bank",
+	"initial_balance",
+	"final_balance",
+	"initial_loss",
+	"credit_loss",
+	"funding_loss",
+	"bank_run_loss",
+	"bank_run_gain",
+	"fire_sale_loss",
+	"total_loss",
+	"default_round",
+	"bankrupt",
+	"default_kind",
+}
+
+// Столбцы клиринга Айзенберга–Ноэ, добавляются после столбцов банка
+var clearingColumns = []string{
+	"liabilities",
+	"payment",
+	"recovery_rate",
+	"recovered",
+}
+
+// columns возвращает столбцы таблицы банков
+func (r *Report) columns() []string {
+	if r.hasClearing() {
+		return append(append([]string(nil), bankColumns...), clearingColumns...)
+	}
+	return bankColumns
+}
+
+// labels возвращает подписи столбцов на языке отчета
+func (r *Report) labels() []string {
+	columns := r.columns()
+	labels := make([]string, len(columns))
+	for i, column := range columns {
+		labels[i] = r.Messages.T("report.column." + column)
+	}
+	return labels
+}
+
+// numeric сообщает, какие столбцы числовые и выравниваются по правому краю
+func (r *Report) numeric() []bool {
+	columns := r.columns()
+	numeric := make([]bool, len(columns))
+	for i := range columns {
+		numeric[i] = (i > 0 && i < len(bankColumns)-3) || i >= len(bankColumns)
+	}
+	return numeric
+}
+
+// cells возвращает значения строки таблицы в порядке columns
+func (r BankRow) cells() []string {
+	name := r.Bank
+	if r.Label != "" {
+		name = fmt.Sprintf("%s (%s)", r.Bank, r.Label)
+	}
+	round := ""
+	if r.DefaultRound >= 0 {
+		round = strconv.Itoa(r.DefaultRound)
+	}
+	cells := []string{
+		name,
+		fmt.Sprintf("%.2f", r.InitialBalance),
+		fmt.Sprintf("%.2f", r.FinalBalance),
+		fmt.Sprintf("%.2f", r.InitialLoss),
+		fmt.Sprintf("%.2f", r.CreditLoss),
+		fmt.Sprintf("%.2f", r.FundingLoss),
+		fmt.Sprintf("%.2f", r.BankRunLoss),
+		fmt.Sprintf("%.2f", r.BankRunGain),
+		fmt.Sprintf("%.2f", r.FireSaleLoss),
+		fmt.Sprintf("%.2f", r.Loss()),
+		round,
+		strconv.FormatBool(r.Bankrupt),
+		r.DefaultKind.String(),
+	}
+	if r.Clearing != nil {
+		cells = append(cells,
+			fmt.Sprintf("%.2f", r.Clearing.Liabilities),
+			fmt.Sprintf("%.2f", r.Clearing.Payment),
+			fmt.Sprintf("%.4f", r.Clearing.RecoveryRate),
+			fmt.Sprintf("%.2f", r.Clearing.Recovered),
+		)
+	}
+	return cells
+}
+
+// labeledCells возвращает значения строки для Markdown и HTML: признак банкротства и причина
+// подписываются на языке отчета, в CSV остаются машинные значения из cells
+func (r *Report) labeledCells(row BankRow) []string {
+	cells := row.cells()
+	cells[len(bankColumns)-2] = r.Messages.T("report.bankrupt." + strconv.FormatBool(row.Bankrupt))
+	cells[len(bankColumns)-1] = ""
+	if row.DefaultKind != contagion.NoDefault {
+		cells[len(bankColumns)-1] = r.Messages.T("default_kind." + row.DefaultKind.String())
+	}
+	return cells
+}
+
+// totalCells возвращает строку итогов в порядке columns
+func (r *Report) totalCells() []string {
+	cells := r.Totals().cells()
+	cells[0] = "total"
+	cells[len(bankColumns)-2] = strconv.Itoa(r.Defaults())
+	cells[len(bankColumns)-1] = ""
+	return cells
+}
+
+// Write записывает отчет в формате format: csv, md или html
+func (r *Report) Write(w io.Writer, format string) error {
+	switch format {
+	case "csv":
+		return r.WriteCSV(w)
+	case "md", "markdown":
+		return r.WriteMarkdown(w)
+	case "html":
+		return r.WriteHTML(w)
+	default:
+		return fmt.Errorf("неизвестный формат отчета: %q", format)
+	}
+}
+
+// FormatOf определяет формат отчета по расширению файла
+func FormatOf(path string) string {
+	return strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+}
+
+// WriteCSV записывает отчет в CSV: параметры строками "# имя,значение", затем таблицу банков и строку total.
+// Строки параметров тоже пишутся через csv.Writer, поэтому значения с запятыми и кавычками экранируются
+func (r *Report) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	for _, param := range r.Parameters {
+		if err := writer.Write([]string{"# " + param.Name, param.Value}); err != nil {
+			return err
+		}
+	}
+
+	if err := writer.Write(r.columns()); err != nil {
+		return err
+	}
+	for _, row := range r.Banks {
+		if err := writer.Write(row.cells()); err != nil {
+			return err
+		}
+	}
+	if err := writer.Write(r.totalCells()); err != nil {
+		return err
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteMarkdown записывает отчет таблицами Markdown
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	if r.Title != "" {
+		fmt.Fprintf(&b, "# %s\n\n", r.Title)
+	}
+
+	fmt.Fprintf(&b, "| %s | %s |\n|---|---|\n", r.Messages.T("report.parameter"), r.Messages.T("report.value"))
+	for _, param := range r.Parameters {
+		fmt.Fprintf(&b, "| %s | %s |\n", param.Name, param.Value)
+	}
+	fmt.Fprintf(&b, "\n%s\n\n", r.Messages.T("report.defaults", r.Defaults(), len(r.Banks)))
+
+	fmt.Fprintf(&b, "| %s |\n|%s\n", strings.Join(r.labels(), " | "), strings.Repeat("---|", len(r.columns())))
+	for _, row := range r.Banks {
+		fmt.Fprintf(&b, "| %s |\n", strings.Join(r.labeledCells(row), " | "))
+	}
+	totals := r.totalCells()
+	totals[0] = "**" + r.Messages.T("report.total") + "**"
+	fmt.Fprintf(&b, "| %s |\n", strings.Join(totals, " | "))
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// htmlTemplate самодостаточная страница отчета без внешних стилей и скриптов
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { border: 1px solid #bbb; padding: 4px 8px; }
+th { background: #eee; }
+td.number { text-align: right; }
+tr.bankrupt { background: #fbe3e3; }
+tr.total { font-weight: bold; background: #f4f4f4; }
+</style>
+</head>
+<body>
+{{if .Title}}<h1>{{.Title}}</h1>{{end}}
+<table>
+<tr><th>{{.ParameterLabel}}</th><th>{{.ValueLabel}}</th></tr>
+{{range .Parameters}}<tr><td>{{.Name}}</td><td>{{.Value}}</td></tr>
+{{end}}</table>
+<p>{{.Defaults}}</p>
+<table>
+<tr>{{range .Labels}}<th>{{.}}</th>{{end}}</tr>
+{{range .Rows}}<tr{{if .Bankrupt}} class="bankrupt"{{end}}>{{range $i, $cell := .Cells}}<td{{if index $.Numeric $i}} class="number"{{end}}>{{$cell}}</td>{{end}}</tr>
+{{end}}<tr class="total">{{range $i, $cell := .Totals}}<td{{if index $.Numeric $i}} class="number"{{end}}>{{$cell}}</td>{{end}}</tr>
+</table>
+</body>
+</html>
+`))
+
+// WriteHTML записывает отчет самодостаточной HTML-страницей
+func (r *Report) WriteHTML(w io.Writer) error {
+	type htmlRow struct {
+		Bankrupt bool
+		Cells    []string
+	}
+
+	rows := make([]htmlRow, len(r.Banks))
+	for i, row := range r.Banks {
+		rows[i] = htmlRow{Bankrupt: row.Bankrupt, Cells: r.labeledCells(row)}
+	}
+	totals := r.totalCells()
+	totals[0] = r.Messages.T("report.total")
+
+	return htmlTemplate.Execute(w, map[string]any{
+		"Lang":           r.Messages.Lang(),
+		"Title":          r.Title,
+		"ParameterLabel": r.Messages.T("report.parameter"),
+		"ValueLabel":     r.Messages.T("report.value"),
+		"Parameters":     r.Parameters,
+		"Defaults":       r.Messages.T("report.defaults", r.Defaults(), len(r.Banks)),
+		"Labels":         r.labels(),
+		"Numeric":        r.numeric(),
+		"Rows":           rows,
+		"Totals":         totals,
+	})
+}
@@ -0,0 +1,180 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nlypage/BankSystemVisualize/contagion"
+	"github.com/nlypage/BankSystemVisualize/locale"
+)
+
+// sample возвращает отчет из двух банков: A обанкротился в начальном шоке, B потерял 40 на шоке фондирования
+// и получил 10 при набеге
+func sample(t *testing.T, lang string) *Report {
+	t.Helper()
+	messages, err := locale.New(lang)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Report{
+		Title: "Ring <5>",
+		Parameters: []Parameter{
+			{"model", "cascade"},
+			{"shock", "A, B: 10.5"},
+			{"note", `say "hi"`},
+		},
+		Banks: []BankRow{
+			{Bank: "A", InitialBalance: 10, FinalBalance: -1, Bankrupt: true, DefaultKind: contagion.InitialDefault, DefaultRound: 0},
+			{Bank: "B", Label: "Beta", InitialBalance: 100, FinalBalance: 70, FundingLoss: 40, BankRunGain: 10, DefaultRound: -1},
+		},
+		Messages: messages,
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := sample(t, "en").WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `# model,cascade
+# shock,"A, B: 10.5"
+# note,"say ""hi"""
+bank,initial_balance,final_balance,initial_loss,credit_loss,funding_loss,bank_run_loss,bank_run_gain,fire_sale_loss,total_loss,default_round,bankrupt,default_kind
+A,10.00,-1.00,0.00,0.00,0.00,0.00,0.00,0.00,0.00,0,true,initial
+B (Beta),100.00,70.00,0.00,0.00,40.00,0.00,10.00,0.00,30.00,,false,none
+total,110.00,69.00,0.00,0.00,40.00,0.00,10.00,0.00,30.00,0,1,
+`
+	if buf.String() != want {
+		t.Errorf("CSV:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	// Строки параметров читаются обычным CSV-парсером и сохраняют значения целиком
+	reader := csv.NewReader(&buf)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := records[1]; !reflect.DeepEqual(got, []string{"# shock", "A, B: 10.5"}) {
+		t.Errorf("shock parameter = %q", got)
+	}
+	if got := records[2]; !reflect.DeepEqual(got, []string{"# note", `say "hi"`}) {
+		t.Errorf("note parameter = %q", got)
+	}
+}
+
+func TestWriteCSVClearing(t *testing.T) {
+	r := sample(t, "en")
+	r.Parameters = nil
+	r.Banks[0].Clearing = &Clearing{Liabilities: 100, Payment: 40, RecoveryRate: 0.4}
+	r.Banks[1].Clearing = &Clearing{Recovered: 40}
+
+	var buf bytes.Buffer
+	if err := r.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if !strings.HasSuffix(lines[0], ",default_kind,liabilities,payment,recovery_rate,recovered") {
+		t.Errorf("header = %s", lines[0])
+	}
+	if !strings.HasSuffix(lines[1], ",initial,100.00,40.00,0.4000,0.00") {
+		t.Errorf("row A = %s", lines[1])
+	}
+	if !strings.HasSuffix(lines[3], ",100.00,40.00,0.4000,40.00") {
+		t.Errorf("total = %s", lines[3])
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := sample(t, "en").WriteMarkdown(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"# Ring <5>\n",
+		"| shock | A, B: 10.5 |\n",
+		"| A | 10.00 | -1.00 | 0.00 | 0.00 | 0.00 | 0.00 | 0.00 | 0.00 | 0.00 | 0 | Yes | Initial default |\n",
+		"| B (Beta) | 100.00 | 70.00 | 0.00 | 0.00 | 40.00 | 0.00 | 10.00 | 0.00 | 30.00 |  | No |  |\n",
+		"| **Total** | 110.00 | 69.00 |",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Markdown does not contain %q:\n%s", want, out)
+		}
+	}
+	// Заголовок, разделитель и три параметра, затем заголовок, разделитель, два банка и итог
+	if rows := strings.Count(out, "\n|"); rows != 5+5 {
+		t.Errorf("got %d table rows", rows)
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := sample(t, "en").WriteHTML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		`<html lang="en">`,
+		"<h1>Ring &lt;5&gt;</h1>",
+		"<td>note</td><td>say &#34;hi&#34;</td>",
+		`<tr class="bankrupt"><td>A</td><td class="number">10.00</td>`,
+		`<td>0</td><td>Yes</td><td>Initial default</td></tr>`,
+		`<tr><td>B (Beta)</td>`,
+		`<tr class="total"><td>Total</td>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("HTML does not contain %q:\n%s", want, out)
+		}
+	}
+	if strings.Count(out, `class="bankrupt"`) != 1 {
+		t.Errorf("expected one bankrupt row")
+	}
+}
+
+func TestWriteFormat(t *testing.T) {
+	tests := []struct {
+		path   string
+		prefix string
+	}{
+		{"out.csv", "# model,cascade"},
+		{"OUT.MD", "# Ring <5>"},
+		{"report.html", "<!DOCTYPE html>"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := sample(t, "en").Write(&buf, FormatOf(tt.path)); err != nil {
+			t.Fatalf("%s: %v", tt.path, err)
+		}
+		if !strings.HasPrefix(buf.String(), tt.prefix) {
+			t.Errorf("%s: output starts with %q", tt.path, buf.String()[:20])
+		}
+	}
+	if err := sample(t, "en").Write(&bytes.Buffer{}, FormatOf("report.pdf")); err == nil {
+		t.Error("no error for an unknown format")
+	}
+}
+
+func TestWriteLocalizedLabels(t *testing.T) {
+	var buf bytes.Buffer
+	if err := sample(t, "ru").WriteMarkdown(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"| 0 | Да | Исходное банкротство |", "| 30.00 |  | Нет |  |", "| **Итого** |"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Markdown does not contain %q:\n%s", want, buf.String())
+		}
+	}
+
+	// В CSV признак банкротства и причина остаются машинными значениями
+	buf.Reset()
+	if err := sample(t, "ru").WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), ",0,true,initial\n") {
+		t.Errorf("CSV has localized values:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,232 @@
+package scenario
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// LoadEdgeList читает сеть требований из файла со списком ребер и необязательного файла балансов
+func LoadEdgeList(edgesPath, balancesPath string) (map[string]BankSpec, error) {
+	edges, err := os.Open(edgesPath)
+	if err != nil {
+		return nil, err
+	}
+	defer edges.Close()
+
+	var balances io.Reader
+	if balancesPath != "" {
+		file, err := os.Open(balancesPath)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		balances = file
+	}
+
+	banks, err := ReadEdgeList(edges, balances)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", edgesPath, err)
+	}
+	return banks, nil
+}
+
+// LoadMatrix читает сеть требований из файла с матрицей
+func LoadMatrix(path string) (map[string]BankSpec, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	banks, err := ReadMatrix(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return banks, nil
+}
+
+// ReadEdgeList читает список ребер from,to,amount: банк from вложил amount в банк to.
+// Балансы читаются из balances в формате bank,balance, и тогда он же задает список банков.
+// Если balances равен nil, банки берутся из ребер с нулевым балансом. Строка заголовка необязательна
+func ReadEdgeList(edges io.Reader, balances io.Reader) (map[string]BankSpec, error) {
+	banks := make(map[string]BankSpec)
+	if balances != nil {
+		err := readRecords(balances, 2, "bank,balance", func(line int, record []string) error {
+			name := strings.TrimSpace(record[0])
+			if name == "" {
+				return fmt.Errorf("балансы, строка %d: пустой идентификатор банка", line)
+			}
+			if _, exists := banks[name]; exists {
+				return fmt.Errorf("балансы, строка %d: банк %q указан повторно", line, name)
+			}
+			balance, err := parseAmount(record[1])
+			if err != nil {
+				return fmt.Errorf("балансы, строка %d: баланс банка %q: %w", line, name, err)
+			}
+			banks[name] = BankSpec{Balance: balance, Dependencies: make(map[string]float64)}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := readRecords(edges, 3, "from,to,amount", func(line int, record []string) error {
+		from, to := strings.TrimSpace(record[0]), strings.TrimSpace(record[1])
+		if from == "" || to == "" {
+			return fmt.Errorf("строка %d: пустой идентификатор банка", line)
+		}
+		if from == to {
+			return fmt.Errorf("строка %d: банк %q не может иметь требование к самому себе", line, from)
+		}
+		for _, name := range []string{from, to} {
+			if _, exists := banks[name]; !exists {
+				if balances != nil {
+					return fmt.Errorf("строка %d: неизвестный банк %q", line, name)
+				}
+				banks[name] = BankSpec{Dependencies: make(map[string]float64)}
+			}
+		}
+		amount, err := parseAmount(record[2])
+		if err != nil {
+			return fmt.Errorf("строка %d: требование %s -> %s: %w", line, from, to, err)
+		}
+		if _, exists := banks[from].Dependencies[to]; exists {
+			return fmt.Errorf("строка %d: требование %s -> %s указано повторно", line, from, to)
+		}
+		banks[from].Dependencies[to] = amount
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return banks, nil
+}
+
+// ReadMatrix читает матрицу требований N×N. Первая строка - заголовок: пустая ячейка или bank,
+// идентификаторы банков и balance. Каждая следующая строка - идентификатор банка, его требования
+// к банкам в порядке заголовка и баланс. Нулевое требование означает отсутствие связи
+func ReadMatrix(r io.Reader) (map[string]BankSpec, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("пустая матрица")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(header) < 3 || strings.TrimSpace(header[len(header)-1]) != "balance" {
+		return nil, fmt.Errorf("строка 1: заголовок должен содержать идентификаторы банков и последний столбец balance")
+	}
+
+	columns := make([]string, 0, len(header)-2)
+	index := make(map[string]int, len(header)-2)
+	for _, cell := range header[1 : len(header)-1] {
+		name := strings.TrimSpace(cell)
+		if name == "" {
+			return nil, fmt.Errorf("строка 1: пустой идентификатор банка")
+		}
+		if _, exists := index[name]; exists {
+			return nil, fmt.Errorf("строка 1: банк %q указан повторно", name)
+		}
+		index[name] = len(columns)
+		columns = append(columns, name)
+	}
+
+	banks := make(map[string]BankSpec, len(columns))
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		if len(record) != len(header) {
+			return nil, fmt.Errorf("строка %d: ожидалось %d столбцов, получено %d", line, len(header), len(record))
+		}
+
+		name := strings.TrimSpace(record[0])
+		if _, exists := index[name]; !exists {
+			return nil, fmt.Errorf("строка %d: неизвестный банк %q", line, name)
+		}
+		if _, exists := banks[name]; exists {
+			return nil, fmt.Errorf("строка %d: банк %q указан повторно", line, name)
+		}
+
+		bank := BankSpec{Dependencies: make(map[string]float64)}
+		for i, cell := range record[1 : len(record)-1] {
+			amount, err := parseAmount(cell)
+			if err != nil {
+				return nil, fmt.Errorf("строка %d: требование %s -> %s: %w", line, name, columns[i], err)
+			}
+			if amount == 0 {
+				continue
+			}
+			if columns[i] == name {
+				return nil, fmt.Errorf("строка %d: банк %q не может иметь требование к самому себе", line, name)
+			}
+			bank.Dependencies[columns[i]] = amount
+		}
+		if bank.Balance, err = parseAmount(record[len(record)-1]); err != nil {
+			return nil, fmt.Errorf("строка %d: баланс банка %q: %w", line, name, err)
+		}
+		banks[name] = bank
+	}
+
+	for _, name := range columns {
+		if _, exists := banks[name]; !exists {
+			return nil, fmt.Errorf("нет строки для банка %q", name)
+		}
+	}
+	return banks, nil
+}
+
+// readRecords читает записи CSV с заданным количеством столбцов и передает их handle вместе с номером строки.
+// Первая запись пропускается, если она совпадает с заголовком header
+func readRecords(r io.Reader, fields int, header string, handle func(line int, record []string) error) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = fields
+	reader.TrimLeadingSpace = true
+
+	first := true
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if first && strings.Join(record, ",") == header {
+			first = false
+			continue
+		}
+		first = false
+
+		line, _ := reader.FieldPos(0)
+		if err := handle(line, record); err != nil {
+			return err
+		}
+	}
+}
+
+// parseAmount разбирает неотрицательную конечную сумму. Номер строки к ошибке добавляет вызывающий код
+func parseAmount(cell string) (float64, error) {
+	amount, err := strconv.ParseFloat(strings.TrimSpace(cell), 64)
+	if err != nil || math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return 0, fmt.Errorf("не число: %q", cell)
+	}
+	if amount < 0 {
+		return 0, fmt.Errorf("отрицательное значение: %g", amount)
+	}
+	return amount, nil
+}
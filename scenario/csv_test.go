@@ -0,0 +1,115 @@
+package scenario
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadEdgeList(t *testing.T) {
+	tests := []struct {
+		name     string
+		edges    string
+		balances string // Пустая строка - балансы не заданы
+		want     map[string]BankSpec
+		hasErr   string // Часть текста ошибки, пустая строка - ошибки нет
+	}{
+		{
+			name:     "with balances",
+			edges:    "from,to,amount\nA,B,3000\nA,C,1000\nB,C,2500\n",
+			balances: "bank,balance\nA,1000\nB, 800\nC,1200\n",
+			want: map[string]BankSpec{
+				"A": {Balance: 1000, Dependencies: map[string]float64{"B": 3000, "C": 1000}},
+				"B": {Balance: 800, Dependencies: map[string]float64{"C": 2500}},
+				"C": {Balance: 1200, Dependencies: map[string]float64{}},
+			},
+		},
+		{
+			name:  "without header and balances",
+			edges: "A,B,10\nB,A,5.5\n",
+			want: map[string]BankSpec{
+				"A": {Dependencies: map[string]float64{"B": 10}},
+				"B": {Dependencies: map[string]float64{"A": 5.5}},
+			},
+		},
+		{name: "self loop", edges: "A,A,10\n", hasErr: "строка 1: банк \"A\" не может иметь требование к самому себе"},
+		{name: "duplicate edge", edges: "from,to,amount\nA,B,1\nA,B,2\n", hasErr: "строка 3: требование A -> B указано повторно"},
+		{name: "negative amount", edges: "A,B,-1\n", hasErr: "строка 1: требование A -> B: отрицательное значение"},
+		{name: "NaN", edges: "A,B,1\nA,C,NaN\n", hasErr: "строка 2: требование A -> C: не число"},
+		{name: "infinity", edges: "A,B,+Inf\n", hasErr: "строка 1: требование A -> B: не число"},
+		{name: "empty bank", edges: "A,,1\n", hasErr: "строка 1: пустой идентификатор банка"},
+		{name: "unknown bank", edges: "A,C,1\n", balances: "A,1\nB,2\n", hasErr: "строка 1: неизвестный банк \"C\""},
+		{name: "infinite balance", edges: "A,B,1\n", balances: "A,1\nB,-Inf\n", hasErr: "балансы, строка 2: баланс банка \"B\": не число"},
+		{name: "duplicate balance", edges: "A,B,1\n", balances: "A,1\nA,2\n", hasErr: "балансы, строка 2: банк \"A\" указан повторно"},
+		{name: "wrong field count", edges: "A,B\n", hasErr: "wrong number of fields"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var balances io.Reader
+			if tt.balances != "" {
+				balances = strings.NewReader(tt.balances)
+			}
+			got, err := ReadEdgeList(strings.NewReader(tt.edges), balances)
+			checkImport(t, got, err, tt.want, tt.hasErr)
+		})
+	}
+}
+
+func TestReadMatrix(t *testing.T) {
+	tests := []struct {
+		name   string
+		matrix string
+		want   map[string]BankSpec
+		hasErr string
+	}{
+		{
+			name:   "matrix",
+			matrix: "bank,A,B,C,balance\nA,0,3000,1000,1000\nB,0,0,2500,800\nC,2000,0,0,1200\n",
+			want: map[string]BankSpec{
+				"A": {Balance: 1000, Dependencies: map[string]float64{"B": 3000, "C": 1000}},
+				"B": {Balance: 800, Dependencies: map[string]float64{"C": 2500}},
+				"C": {Balance: 1200, Dependencies: map[string]float64{"A": 2000}},
+			},
+		},
+		{
+			name:   "empty corner cell",
+			matrix: ",A,B,balance\nB,1,0,5\nA,0,2,7\n",
+			want: map[string]BankSpec{
+				"A": {Balance: 7, Dependencies: map[string]float64{"B": 2}},
+				"B": {Balance: 5, Dependencies: map[string]float64{"A": 1}},
+			},
+		},
+		{name: "empty", matrix: "", hasErr: "пустая матрица"},
+		{name: "no balance column", matrix: "bank,A,B\nA,0,1\n", hasErr: "строка 1: заголовок"},
+		{name: "self loop", matrix: "bank,A,B,balance\nA,1,0,5\nB,0,0,5\n", hasErr: "строка 2: банк \"A\" не может иметь требование к самому себе"},
+		{name: "NaN", matrix: "bank,A,B,balance\nA,0,1,5\nB,NaN,0,5\n", hasErr: "строка 3: требование B -> A: не число"},
+		{name: "infinite balance", matrix: "bank,A,B,balance\nA,0,1,Inf\nB,0,0,5\n", hasErr: "строка 2: баланс банка \"A\": не число"},
+		{name: "missing row", matrix: "bank,A,B,balance\nA,0,1,5\n", hasErr: "нет строки для банка \"B\""},
+		{name: "short row", matrix: "bank,A,B,balance\nA,0,1\n", hasErr: "строка 2: ожидалось 4 столбцов, получено 3"},
+		{name: "unknown bank", matrix: "bank,A,B,balance\nC,0,1,5\n", hasErr: "строка 2: неизвестный банк \"C\""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadMatrix(strings.NewReader(tt.matrix))
+			checkImport(t, got, err, tt.want, tt.hasErr)
+		})
+	}
+}
+
+// checkImport сравнивает результат импорта с ожидаемыми банками или ошибкой
+func checkImport(t *testing.T, got map[string]BankSpec, err error, want map[string]BankSpec, hasErr string) {
+	t.Helper()
+	if hasErr != "" {
+		if err == nil || !strings.Contains(err.Error(), hasErr) {
+			t.Fatalf("error = %v, want error containing %q", err, hasErr)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("banks = %+v, want %+v", got, want)
+	}
+}
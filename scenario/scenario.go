@@ -0,0 +1,386 @@
+// Package scenario загружает сценарии стресс-тестов из JSON: банки, требования, параметры модели и начальный шок
+package scenario
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/nlypage/BankSystemVisualize/contagion"
+	"github.com/nlypage/BankSystemVisualize/topology"
+)
+
+// Scenario описание эксперимента
+type Scenario struct {
+	Name       string              `json:"name,omitempty"`
+	Topology   *Topology           `json:"topology,omitempty"` // Сгенерированная сеть, банки из Banks заменяют сгенерированные
+	Banks      map[string]BankSpec `json:"banks,omitempty"`
+	Market     *MarketSpec         `json:"market,omitempty"`
+	Parameters Parameters          `json:"parameters"`
+	Shock      Shock               `json:"shock"`
+	Font       *Font               `json:"font,omitempty"` // Шрифт визуализатора
+}
+
+// Font шрифт визуализатора
+type Font struct {
+	Path string  `json:"path,omitempty"` // Файл TTF или OTF, относительный путь считается от файла сценария
+	Size float64 `json:"size,omitempty"`
+}
+
+// BankSpec описание банка
+type BankSpec struct {
+	Label          string             `json:"label,omitempty"`
+	Balance        float64            `json:"balance"`
+	Dependencies   map[string]float64 `json:"dependencies,omitempty"`
+	CreditLoss     map[string]float64 `json:"credit_loss,omitempty"`
+	FundingLoss    map[string]float64 `json:"funding_loss,omitempty"`
+	ExternalAssets float64            `json:"external_assets,omitempty"`
+	Cash           float64            `json:"cash,omitempty"`
+	Deposits       float64            `json:"deposits,omitempty"`
+	Holdings       map[string]float64 `json:"holdings,omitempty"`
+	Position       *Position          `json:"position,omitempty"`
+}
+
+// Position координаты банка на экране визуализатора
+type Position struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Topology параметры генератора сети из пакета topology
+type Topology struct {
+	Kind     string  `json:"kind"` // complete, ring, star, core-periphery, erdos-renyi, barabasi-albert, watts-strogatz
+	Banks    int     `json:"banks"`
+	Core     int     `json:"core,omitempty"` // Размер ядра для core-periphery
+	Balance  float64 `json:"balance"`        // Баланс каждого банка (X)
+	Exposure float64 `json:"exposure"`       // Сумма требований каждого банка (Y)
+	Prob     float64 `json:"prob,omitempty"` // Вероятность связи для erdos-renyi
+	M        int     `json:"m,omitempty"`    // Количество связей нового банка для barabasi-albert
+	K        int     `json:"k,omitempty"`    // Количество соседей для watts-strogatz
+	Beta     float64 `json:"beta,omitempty"` // Вероятность перенаправления для watts-strogatz
+	Seed     int64   `json:"seed,omitempty"` // Зерно для случайных топологий
+}
+
+// MarketSpec рынок внешних активов для канала вынужденных продаж
+type MarketSpec struct {
+	Prices map[string]float64 `json:"prices"`
+	Impact string             `json:"impact,omitempty"` // linear или exponential (по умолчанию)
+	Alpha  float64            `json:"alpha,omitempty"`  // Сила влияния продаж на цену, по умолчанию 1
+}
+
+// Parameters параметры модели
+type Parameters struct {
+	LambdaC         float64 `json:"lambda_c"`
+	LambdaF         float64 `json:"lambda_f"`
+	PanicRate       float64 `json:"panic_rate"`
+	EnablePanic     bool    `json:"enable_panic"`
+	EnableLiquidity bool    `json:"enable_liquidity,omitempty"`
+	CapitalRatio    float64 `json:"capital_ratio,omitempty"`
+	Model           string  `json:"model,omitempty"`  // cascade (по умолчанию), eisenberg-noe, debtrank
+	Order           string  `json:"order,omitempty"`  // name (по умолчанию), exposure, random
+	Seed            int64   `json:"seed,omitempty"`   // Зерно для порядка random
+	Update          string  `json:"update,omitempty"` // sequential (по умолчанию), synchronous или immediate
+}
+
+// Shock начальный шок
+type Shock struct {
+	Banks  []string           `json:"banks"`            // Банки, объявляемые банкротами
+	Losses map[string]float64 `json:"losses,omitempty"` // Частичные потери баланса остальных банков
+}
+
+// Load читает сценарий из файла
+func Load(path string) (*Scenario, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	sc, err := Parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if sc.Font != nil && sc.Font.Path != "" && !filepath.IsAbs(sc.Font.Path) {
+		sc.Font.Path = filepath.Join(filepath.Dir(path), sc.Font.Path)
+	}
+	return sc, nil
+}
+
+// Parse читает сценарий в формате JSON. Неизвестные поля считаются ошибкой, чтобы опечатки не терялись
+func Parse(r io.Reader) (*Scenario, error) {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+
+	var sc Scenario
+	if err := decoder.Decode(&sc); err != nil {
+		return nil, err
+	}
+	return &sc, nil
+}
+
+// Write записывает сценарий в формате JSON
+func (sc *Scenario) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sc)
+}
+
+// Expand возвращает копию сценария, в которой сгенерированная топология заменена явным списком банков,
+// чтобы сценарий можно было отредактировать вручную
+func (sc *Scenario) Expand() (*Scenario, error) {
+	system, err := sc.System()
+	if err != nil {
+		return nil, err
+	}
+
+	expanded := *sc
+	expanded.Topology = nil
+	expanded.Banks = make(map[string]BankSpec, len(system.Banks))
+	for name, bank := range system.Banks {
+		spec := BankSpec{
+			Label:          bank.Label,
+			Balance:        bank.Balance,
+			Dependencies:   bank.Dependencies,
+			CreditLoss:     omitEmpty(bank.CreditLoss),
+			FundingLoss:    omitEmpty(bank.FundingLoss),
+			ExternalAssets: bank.ExternalAssets,
+			Cash:           bank.Cash,
+			Deposits:       bank.Deposits,
+			Holdings:       omitEmpty(bank.Holdings),
+		}
+		if original, exists := sc.Banks[name]; exists {
+			spec.Position = original.Position
+		}
+		expanded.Banks[name] = spec
+	}
+	return &expanded, nil
+}
+
+// omitEmpty возвращает nil для пустого словаря, чтобы он не попадал в JSON
+func omitEmpty(amounts map[string]float64) map[string]float64 {
+	if len(amounts) == 0 {
+		return nil
+	}
+	return amounts
+}
+
+// HasPositions сообщает, заданы ли координаты всех банков сценария
+func (sc *Scenario) HasPositions() bool {
+	if sc.Topology != nil || len(sc.Banks) == 0 {
+		return false
+	}
+	for _, spec := range sc.Banks {
+		if spec.Position == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// System строит банковскую систему по сценарию и проверяет ее корректность
+func (sc *Scenario) System() (*contagion.BankSystem, error) {
+	banks := make(map[string]contagion.Bank)
+	if sc.Topology != nil {
+		generated, err := sc.Topology.generate()
+		if err != nil {
+			return nil, err
+		}
+		banks = generated
+	}
+	for name, spec := range sc.Banks {
+		bank := contagion.Bank{
+			Label:          spec.Label,
+			Balance:        spec.Balance,
+			Dependencies:   spec.Dependencies,
+			CreditLoss:     spec.CreditLoss,
+			FundingLoss:    spec.FundingLoss,
+			ExternalAssets: spec.ExternalAssets,
+			Cash:           spec.Cash,
+			Deposits:       spec.Deposits,
+			Holdings:       spec.Holdings,
+		}
+		if spec.Position != nil {
+			bank.X, bank.Y = spec.Position.X, spec.Position.Y
+		}
+		banks[name] = bank
+	}
+	banks = contagion.CloneBanks(banks)
+	if len(banks) == 0 {
+		return nil, fmt.Errorf("в сценарии нет банков")
+	}
+	if err := validate(banks); err != nil {
+		return nil, err
+	}
+
+	for _, name := range sc.Shock.Banks {
+		if _, exists := banks[name]; !exists {
+			return nil, fmt.Errorf("шок: неизвестный банк %q", name)
+		}
+	}
+	for name, loss := range sc.Shock.Losses {
+		if _, exists := banks[name]; !exists {
+			return nil, fmt.Errorf("шок: неизвестный банк %q", name)
+		}
+		if loss < 0 {
+			return nil, fmt.Errorf("шок: отрицательные потери банка %q: %g", name, loss)
+		}
+	}
+
+	params := sc.Parameters
+	// Параметры модели - доли, они должны лежать на отрезке [0, 1]
+	for _, param := range []struct {
+		name  string
+		value float64
+	}{
+		{"lambda_c", params.LambdaC},
+		{"lambda_f", params.LambdaF},
+		{"panic_rate", params.PanicRate},
+		{"capital_ratio", params.CapitalRatio},
+	} {
+		if !(param.value >= 0 && param.value <= 1) {
+			return nil, fmt.Errorf("параметр %s должен быть от 0 до 1: %g", param.name, param.value)
+		}
+	}
+
+	system := &contagion.BankSystem{
+		LambdaC:         params.LambdaC,
+		LambdaF:         params.LambdaF,
+		EnablePanic:     params.EnablePanic,
+		PanicRate:       params.PanicRate,
+		EnableLiquidity: params.EnableLiquidity,
+		CapitalRatio:    params.CapitalRatio,
+		Banks:           banks,
+	}
+
+	var err error
+	if params.Model != "" {
+		if system.Model, err = contagion.ParseModel(params.Model); err != nil {
+			return nil, err
+		}
+	}
+	if params.Order != "" {
+		if system.Order, err = contagion.ParseOrder(params.Order, params.Seed); err != nil {
+			return nil, err
+		}
+	}
+	if params.Update != "" {
+		if system.Update, err = contagion.ParseUpdateMode(params.Update); err != nil {
+			return nil, err
+		}
+	}
+	if sc.Market != nil {
+		if system.Market, err = sc.Market.market(); err != nil {
+			return nil, err
+		}
+	}
+	return system, nil
+}
+
+// validate проверяет, что требования ссылаются на существующие банки и не отрицательны,
+// а доли потерь не превышают единицу
+func validate(banks map[string]contagion.Bank) error {
+	names := make([]string, 0, len(banks))
+	for name := range banks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		bank := banks[name]
+		for i, amounts := range []map[string]float64{bank.Dependencies, bank.CreditLoss, bank.FundingLoss} {
+			for debtor, amount := range amounts {
+				if _, exists := banks[debtor]; !exists {
+					return fmt.Errorf("банк %q: требование к неизвестному банку %q", name, debtor)
+				}
+				if amount < 0 {
+					return fmt.Errorf("банк %q: отрицательное значение для банка %q: %g", name, debtor, amount)
+				}
+				// CreditLoss и FundingLoss - доли потерь, они не могут превышать единицу
+				if i > 0 && amount > 1 {
+					return fmt.Errorf("банк %q: доля потерь для банка %q больше единицы: %g", name, debtor, amount)
+				}
+			}
+		}
+		for asset, units := range bank.Holdings {
+			if units < 0 {
+				return fmt.Errorf("банк %q: отрицательное количество актива %q: %g", name, asset, units)
+			}
+		}
+	}
+	return nil
+}
+
+// generate строит банки генератором из пакета topology
+func (t *Topology) generate() (map[string]contagion.Bank, error) {
+	if t.Banks <= 0 {
+		return nil, fmt.Errorf("топология: количество банков должно быть положительным, получено %d", t.Banks)
+	}
+
+	rules := topology.Uniform(t.Balance, t.Exposure)
+	switch t.Kind {
+	case "complete":
+		return topology.Complete(t.Banks, rules), nil
+	case "ring":
+		return topology.Ring(t.Banks, rules), nil
+	case "star":
+		return topology.Star(t.Banks, rules), nil
+	case "core-periphery":
+		if t.Core < 0 || t.Core > t.Banks {
+			return nil, fmt.Errorf("топология: размер ядра должен быть от 0 до %d, получено %d", t.Banks, t.Core)
+		}
+		return topology.CorePeriphery(t.Core, t.Banks-t.Core, rules), nil
+	case "erdos-renyi":
+		if t.Prob < 0 || t.Prob > 1 {
+			return nil, fmt.Errorf("топология: вероятность связи должна быть от 0 до 1, получено %g", t.Prob)
+		}
+		return topology.ErdosRenyi(t.Banks, t.Prob, rules, t.Seed), nil
+	case "barabasi-albert":
+		if t.M < 0 {
+			return nil, fmt.Errorf("топология: количество связей нового банка не может быть отрицательным, получено %d", t.M)
+		}
+		return topology.BarabasiAlbert(t.Banks, t.M, rules, t.Seed), nil
+	case "watts-strogatz":
+		if t.K < 0 || t.K >= t.Banks {
+			return nil, fmt.Errorf("топология: количество соседей должно быть от 0 до %d, получено %d", t.Banks-1, t.K)
+		}
+		if t.Beta < 0 || t.Beta > 1 {
+			return nil, fmt.Errorf("топология: вероятность перенаправления должна быть от 0 до 1, получено %g", t.Beta)
+		}
+		return topology.WattsStrogatz(t.Banks, t.K, t.Beta, rules, t.Seed), nil
+	default:
+		return nil, fmt.Errorf("неизвестная топология: %q", t.Kind)
+	}
+}
+
+// market строит рынок внешних активов
+func (m *MarketSpec) market() (*contagion.Market, error) {
+	alpha := m.Alpha
+	if alpha == 0 {
+		alpha = 1
+	}
+
+	assets := make([]string, 0, len(m.Prices))
+	for asset := range m.Prices {
+		assets = append(assets, asset)
+	}
+	sort.Strings(assets)
+	for _, asset := range assets {
+		if price := m.Prices[asset]; !(price > 0) {
+			return nil, fmt.Errorf("рынок: цена актива %q должна быть положительной, получено %g", asset, price)
+		}
+	}
+
+	market := &contagion.Market{Prices: m.Prices}
+	switch m.Impact {
+	case "", "exponential":
+		market.Impact = contagion.ExponentialImpact(alpha)
+	case "linear":
+		market.Impact = contagion.LinearImpact(alpha)
+	default:
+		return nil, fmt.Errorf("неизвестная функция влияния на цену: %q", m.Impact)
+	}
+	return market, nil
+}
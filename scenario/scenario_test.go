@@ -0,0 +1,139 @@
+package scenario
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTopologyGenerate(t *testing.T) {
+	tests := []struct {
+		name     string
+		topology Topology
+		banks    int
+		hasErr   bool
+	}{
+		{name: "ring", topology: Topology{Kind: "ring", Banks: 5}, banks: 5},
+		{name: "core-periphery", topology: Topology{Kind: "core-periphery", Banks: 6, Core: 2}, banks: 6},
+		{name: "core only", topology: Topology{Kind: "core-periphery", Banks: 3, Core: 3}, banks: 3},
+		{name: "core larger than banks", topology: Topology{Kind: "core-periphery", Banks: 3, Core: 4}, hasErr: true},
+		{name: "negative core", topology: Topology{Kind: "core-periphery", Banks: 3, Core: -1}, hasErr: true},
+		{name: "erdos-renyi", topology: Topology{Kind: "erdos-renyi", Banks: 10, Prob: 1}, banks: 10},
+		{name: "probability above one", topology: Topology{Kind: "erdos-renyi", Banks: 10, Prob: 1.5}, hasErr: true},
+		{name: "negative probability", topology: Topology{Kind: "erdos-renyi", Banks: 10, Prob: -0.1}, hasErr: true},
+		{name: "barabasi-albert", topology: Topology{Kind: "barabasi-albert", Banks: 10, M: 2}, banks: 10},
+		{name: "negative m", topology: Topology{Kind: "barabasi-albert", Banks: 10, M: -1}, hasErr: true},
+		{name: "watts-strogatz", topology: Topology{Kind: "watts-strogatz", Banks: 10, K: 4, Beta: 0.2}, banks: 10},
+		{name: "negative k", topology: Topology{Kind: "watts-strogatz", Banks: 10, K: -2}, hasErr: true},
+		{name: "k equal to banks", topology: Topology{Kind: "watts-strogatz", Banks: 10, K: 10}, hasErr: true},
+		{name: "beta above one", topology: Topology{Kind: "watts-strogatz", Banks: 10, K: 4, Beta: 2}, hasErr: true},
+		{name: "no banks", topology: Topology{Kind: "ring"}, hasErr: true},
+		{name: "unknown kind", topology: Topology{Kind: "tree", Banks: 3}, hasErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			banks, err := tt.topology.generate()
+			if (err != nil) != tt.hasErr {
+				t.Fatalf("error = %v, want error %t", err, tt.hasErr)
+			}
+			if len(banks) != tt.banks {
+				t.Errorf("got %d banks, want %d", len(banks), tt.banks)
+			}
+		})
+	}
+}
+
+func TestSystemValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		json   string
+		hasErr string // Часть текста ошибки, пустая строка - ошибки нет
+	}{
+		{
+			name: "valid",
+			json: `{"banks": {"A": {"balance": 10, "dependencies": {"B": 5}, "credit_loss": {"B": 1}, "holdings": {"bond": 2}},
+				"B": {"balance": 10}}, "market": {"prices": {"bond": 10}}, "shock": {"banks": ["A"]}}`,
+		},
+		{
+			name:   "unknown debtor",
+			json:   `{"banks": {"A": {"balance": 10, "dependencies": {"C": 5}}}}`,
+			hasErr: "неизвестному банку",
+		},
+		{
+			name:   "negative exposure",
+			json:   `{"banks": {"A": {"balance": 10, "dependencies": {"B": -5}}, "B": {}}}`,
+			hasErr: "отрицательное значение",
+		},
+		{
+			name:   "credit loss above one",
+			json:   `{"banks": {"A": {"balance": 10, "dependencies": {"B": 5}, "credit_loss": {"B": 1.5}}, "B": {}}}`,
+			hasErr: "больше единицы",
+		},
+		{
+			name:   "funding loss above one",
+			json:   `{"banks": {"A": {"balance": 10}, "B": {"funding_loss": {"A": 2}}}}`,
+			hasErr: "больше единицы",
+		},
+		{
+			name:   "zero price",
+			json:   `{"banks": {"A": {"balance": 10}}, "market": {"prices": {"bond": 0}}}`,
+			hasErr: "цена актива",
+		},
+		{
+			name:   "negative price",
+			json:   `{"banks": {"A": {"balance": 10}}, "market": {"prices": {"bond": -1}}}`,
+			hasErr: "цена актива",
+		},
+		{
+			name:   "invalid topology",
+			json:   `{"topology": {"kind": "core-periphery", "banks": 2, "core": 5}}`,
+			hasErr: "размер ядра",
+		},
+		{
+			name:   "lambda_c above one",
+			json:   `{"banks": {"A": {"balance": 10}}, "parameters": {"lambda_c": 7}}`,
+			hasErr: "lambda_c",
+		},
+		{
+			name:   "negative lambda_f",
+			json:   `{"banks": {"A": {"balance": 10}}, "parameters": {"lambda_f": -0.5}}`,
+			hasErr: "lambda_f",
+		},
+		{
+			name:   "negative panic rate",
+			json:   `{"banks": {"A": {"balance": 10}}, "parameters": {"panic_rate": -3}}`,
+			hasErr: "panic_rate",
+		},
+		{
+			name:   "capital ratio above one",
+			json:   `{"banks": {"A": {"balance": 10}}, "parameters": {"capital_ratio": 1.2}}`,
+			hasErr: "capital_ratio",
+		},
+		{
+			name: "parameters at the bounds",
+			json: `{"banks": {"A": {"balance": 10}}, "parameters": {"lambda_c": 1, "lambda_f": 0, "panic_rate": 1, "capital_ratio": 0}}`,
+		},
+		{
+			name:   "unknown shocked bank",
+			json:   `{"banks": {"A": {"balance": 10}}, "shock": {"banks": ["B"]}}`,
+			hasErr: "шок",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc, err := Parse(strings.NewReader(tt.json))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = sc.System()
+			if tt.hasErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.hasErr) {
+				t.Errorf("error = %v, want error containing %q", err, tt.hasErr)
+			}
+		})
+	}
+}
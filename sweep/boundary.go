@@ -0,0 +1,77 @@
+// Package sweep перебирает параметры модели и ищет границы между режимами распространения банкротств
+package sweep
+
+import "github.com/nlypage/BankSystemVisualize/contagion"
+
+// BoundaryPoint точка границы между режимами без заражения и с заражением
+type BoundaryPoint struct {
+	P      float64 // Доля закрываемых вкладов
+	Lambda float64 // Доля потерь при кредитном шоке и шоке фондирования
+	Found  bool    // false, если на всем отрезке поиска количество банкротств не меняется
+}
+
+// Bisect ищет наименьшее значение x на отрезке [low, high], при котором count(x) превышает count(low),
+// с точностью precision. Предполагается, что после скачка количество банкротств не убывает
+func Bisect(count func(x float64) int, low, high, precision float64) (float64, bool) {
+	base := count(low)
+	if count(high) <= base {
+		return high, false
+	}
+
+	for high-low > precision {
+		mid := (low + high) / 2
+		if count(mid) > base {
+			high = mid
+		} else {
+			low = mid
+		}
+	}
+	return high, true
+}
+
+// run запускает стресс-тест на копии системы base с заданными p и lambda
+func run(base *contagion.BankSystem, trigger string, p, lambda float64) int {
+	system := base.Clone()
+	system.PanicRate = p
+	system.LambdaC = lambda
+	system.LambdaF = lambda
+	return system.StressTest(trigger)
+}
+
+// CriticalLambda ищет критическое значение lambda на отрезке [0, 1] при фиксированном p
+func CriticalLambda(base *contagion.BankSystem, trigger string, p, precision float64) (float64, bool) {
+	return Bisect(func(lambda float64) int {
+		return run(base, trigger, p, lambda)
+	}, 0, 1, precision)
+}
+
+// CriticalP ищет критическое значение p на отрезке [0, 1] при фиксированном lambda
+func CriticalP(base *contagion.BankSystem, trigger string, lambda, precision float64) (float64, bool) {
+	return Bisect(func(p float64) int {
+		return run(base, trigger, p, lambda)
+	}, 0, 1, precision)
+}
+
+// LambdaBoundary строит границу режимов: для steps+1 равноотстоящих значений p на [0, 1]
+// находит критическое lambda
+func LambdaBoundary(base *contagion.BankSystem, trigger string, steps int, precision float64) []BoundaryPoint {
+	boundary := make([]BoundaryPoint, 0, steps+1)
+	for i := 0; i <= steps; i++ {
+		p := float64(i) / float64(steps)
+		lambda, found := CriticalLambda(base, trigger, p, precision)
+		boundary = append(boundary, BoundaryPoint{P: p, Lambda: lambda, Found: found})
+	}
+	return boundary
+}
+
+// PBoundary строит границу режимов: для steps+1 равноотстоящих значений lambda на [0, 1]
+// находит критическое p
+func PBoundary(base *contagion.BankSystem, trigger string, steps int, precision float64) []BoundaryPoint {
+	boundary := make([]BoundaryPoint, 0, steps+1)
+	for i := 0; i <= steps; i++ {
+		lambda := float64(i) / float64(steps)
+		p, found := CriticalP(base, trigger, lambda, precision)
+		boundary = append(boundary, BoundaryPoint{P: p, Lambda: lambda, Found: found})
+	}
+	return boundary
+}
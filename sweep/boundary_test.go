@@ -0,0 +1,85 @@
+package sweep
+
+import (
+	"testing"
+
+	"github.com/nlypage/BankSystemVisualize/contagion"
+	"github.com/nlypage/BankSystemVisualize/topology"
+)
+
+func TestBisect(t *testing.T) {
+	tests := []struct {
+		name      string
+		count     func(x float64) int
+		found     bool
+		threshold float64
+	}{
+		{"jump", func(x float64) int {
+			if x > 0.3 {
+				return 5
+			}
+			return 1
+		}, true, 0.3},
+		{"jump at the end", func(x float64) int {
+			if x >= 1 {
+				return 2
+			}
+			return 1
+		}, true, 1},
+		{"no transition", func(float64) int { return 3 }, false, 1},
+	}
+	const precision = 1e-4
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x, found := Bisect(tt.count, 0, 1, precision)
+			if found != tt.found {
+				t.Fatalf("found = %t, want %t", found, tt.found)
+			}
+			if x < tt.threshold || x > tt.threshold+precision {
+				t.Errorf("x = %g, want within [%g, %g]", x, tt.threshold, tt.threshold+precision)
+			}
+		})
+	}
+}
+
+// ring возвращает кольцо из 5 банков с балансом 1000 и требованиями 5000
+func ring() *contagion.BankSystem {
+	return &contagion.BankSystem{
+		EnablePanic: true,
+		Banks:       topology.Ring(5, topology.Uniform(1000, 5000)),
+	}
+}
+
+func TestCriticalLambda(t *testing.T) {
+	const precision = 1e-3
+	for _, p := range []float64{0, 0.3, 0.6} {
+		lambda, found := CriticalLambda(ring(), "1", p, precision)
+		if !found {
+			t.Fatalf("p = %g: no transition", p)
+		}
+		base := run(ring(), "1", p, 0)
+		if run(ring(), "1", p, lambda) <= base {
+			t.Errorf("p = %g: lambda %g does not increase defaults", p, lambda)
+		}
+		if run(ring(), "1", p, lambda-precision) > base {
+			t.Errorf("p = %g: lambda %g is not the smallest critical value", p, lambda)
+		}
+	}
+}
+
+func TestBoundaryPoints(t *testing.T) {
+	points := LambdaBoundary(ring(), "1", 4, 1e-3)
+	if len(points) != 5 {
+		t.Fatalf("got %d points, want 5", len(points))
+	}
+	for i, point := range points {
+		if want := float64(i) / 4; point.P != want {
+			t.Errorf("point %d: p = %g, want %g", i, point.P, want)
+		}
+	}
+
+	points = PBoundary(ring(), "1", 2, 1e-3)
+	if len(points) != 3 || points[1].Lambda != 0.5 {
+		t.Errorf("PBoundary points = %+v", points)
+	}
+}
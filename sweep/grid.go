@@ -0,0 +1,293 @@
+package sweep
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/nlypage/BankSystemVisualize/contagion"
+)
+
+// Axis ось сетки параметров
+type Axis struct {
+	Name   string    // Имя параметра из Parameters
+	Values []float64 // Значения параметра
+}
+
+// Range возвращает ось из steps+1 равноотстоящих значений от from до to.
+// Значения считаются от индекса, поэтому ошибка округления не накапливается, а концы совпадают точно
+func Range(name string, from, to float64, steps int) Axis {
+	if steps <= 0 {
+		return Axis{Name: name, Values: []float64{from}}
+	}
+	values := make([]float64, 0, steps+1)
+	for i := 0; i <= steps; i++ {
+		values = append(values, (from*float64(steps-i)+to*float64(i))/float64(steps))
+	}
+	return Axis{Name: name, Values: values}
+}
+
+// ParseAxis разбирает ось из строки вида "имя=начало:конец:шаг" (конец включается, если до него
+// укладывается целое число шагов) или "имя=значение,значение,..."
+func ParseAxis(spec string) (Axis, error) {
+	name, values, found := strings.Cut(spec, "=")
+	name = strings.TrimSpace(name)
+	if !found || name == "" || values == "" {
+		return Axis{}, fmt.Errorf("ось %q: ожидается имя=начало:конец:шаг или имя=значение,значение", spec)
+	}
+	if _, exists := Parameters[name]; !exists {
+		return Axis{}, fmt.Errorf("ось %q: неизвестный параметр %q", spec, name)
+	}
+
+	if parts := strings.Split(values, ":"); len(parts) > 1 {
+		if len(parts) != 3 {
+			return Axis{}, fmt.Errorf("ось %q: диапазон задается как начало:конец:шаг", spec)
+		}
+		var bounds [3]float64
+		for i, part := range parts {
+			value, err := parseValue(part)
+			if err != nil {
+				return Axis{}, fmt.Errorf("ось %q: %w", spec, err)
+			}
+			bounds[i] = value
+		}
+		from, to, step := bounds[0], bounds[1], bounds[2]
+		if step <= 0 || to < from {
+			return Axis{}, fmt.Errorf("ось %q: нужен положительный шаг и конец не меньше начала", spec)
+		}
+		// Допуск нужен, чтобы конец вида 1.0 при шаге 0.1 не терялся из-за округления
+		steps := int(math.Floor((to-from)/step + 1e-9))
+		return Range(name, from, from+float64(steps)*step, steps), nil
+	}
+
+	axis := Axis{Name: name}
+	for _, part := range strings.Split(values, ",") {
+		value, err := parseValue(part)
+		if err != nil {
+			return Axis{}, fmt.Errorf("ось %q: %w", spec, err)
+		}
+		axis.Values = append(axis.Values, value)
+	}
+	return axis, nil
+}
+
+// parseValue разбирает конечное число
+func parseValue(s string) (float64, error) {
+	value, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, fmt.Errorf("не число: %q", s)
+	}
+	return value, nil
+}
+
+// Parameters параметры модели, которые можно перебирать, и способ их установки в систему.
+// Параметр shock обрабатывается отдельно: это доля баланса, которую теряет банк-триггер
+var Parameters = map[string]func(s *contagion.BankSystem, value float64){
+	"p": func(s *contagion.BankSystem, value float64) {
+		s.PanicRate = value
+	},
+	"lambda": func(s *contagion.BankSystem, value float64) {
+		s.LambdaC = value
+		s.LambdaF = value
+	},
+	"lambda_c": func(s *contagion.BankSystem, value float64) {
+		s.LambdaC = value
+	},
+	"lambda_f": func(s *contagion.BankSystem, value float64) {
+		s.LambdaF = value
+	},
+	"capital_ratio": func(s *contagion.BankSystem, value float64) {
+		s.CapitalRatio = value
+	},
+	"seed": func(s *contagion.BankSystem, value float64) {
+		s.Order.Seed = int64(value)
+	},
+	"shock": func(*contagion.BankSystem, float64) {},
+}
+
+// overlaps параметры, которые задают одно и то же поле системы и не могут быть осями одной сетки
+var overlaps = map[string][]string{
+	"lambda":   {"lambda_c", "lambda_f"},
+	"lambda_c": {"lambda"},
+	"lambda_f": {"lambda"},
+}
+
+// Config параметры перебора
+type Config struct {
+	Grid       []Axis                           // Оси сетки, перебирается их декартово произведение
+	Topologies map[string]*contagion.BankSystem // Исходные системы, каждая точка считается на их копиях
+	Trigger    string                           // Банк, с которого начинается стресс-тест
+	Workers    int                              // Количество параллельных обработчиков, 0 - по числу ядер
+}
+
+// Result полный результат одной точки сетки для одной топологии
+type Result struct {
+	Topology string
+	Params   []float64 // Значения параметров в порядке осей сетки
+	Defaults int       // Количество обанкротившихся банков
+	Loss     float64   // Суммарное снижение балансов
+	Rounds   int       // Количество уровней каскада после исходного банкротства
+	Model    string
+	Order    string
+	Update   string
+}
+
+// task одна точка сетки для одной топологии
+type task struct {
+	index    int
+	topology string
+	params   []float64
+}
+
+// Run перебирает все точки сетки для всех топологий на пуле обработчиков.
+// Результаты возвращаются в детерминированном порядке: по точкам сетки, внутри точки по имени топологии
+func Run(cfg Config) ([]Result, error) {
+	names := make(map[string]bool, len(cfg.Grid))
+	for _, axis := range cfg.Grid {
+		if _, exists := Parameters[axis.Name]; !exists {
+			return nil, fmt.Errorf("неизвестный параметр сетки: %q", axis.Name)
+		}
+		if names[axis.Name] {
+			return nil, fmt.Errorf("параметр сетки %q указан дважды", axis.Name)
+		}
+		for _, other := range overlaps[axis.Name] {
+			if names[other] {
+				return nil, fmt.Errorf("параметры сетки %q и %q задают одно и то же", other, axis.Name)
+			}
+		}
+		if len(axis.Values) == 0 {
+			return nil, fmt.Errorf("у параметра сетки %q нет значений", axis.Name)
+		}
+		names[axis.Name] = true
+	}
+
+	topologies := make([]string, 0, len(cfg.Topologies))
+	for name := range cfg.Topologies {
+		topologies = append(topologies, name)
+	}
+	sort.Strings(topologies)
+
+	points := product(cfg.Grid)
+	results := make([]Result, len(points)*len(topologies))
+
+	workers := cfg.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	tasks := make(chan task)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for t := range tasks {
+				results[t.index] = runPoint(cfg, t.topology, t.params)
+			}
+		}()
+	}
+
+	for i, params := range points {
+		for j, topology := range topologies {
+			tasks <- task{index: i*len(topologies) + j, topology: topology, params: params}
+		}
+	}
+	close(tasks)
+	wg.Wait()
+
+	return results, nil
+}
+
+// runPoint запускает стресс-тест в одной точке сетки на копии системы
+func runPoint(cfg Config, topology string, params []float64) Result {
+	base := cfg.Topologies[topology]
+	system := base.Clone()
+	shock := 1.0
+	for i, axis := range cfg.Grid {
+		if axis.Name == "shock" {
+			shock = params[i]
+			continue
+		}
+		Parameters[axis.Name](system, params[i])
+	}
+
+	rounds := 0
+	system.Subscribe(contagion.ObserverFunc(func(e contagion.Event) {
+		rounds = max(rounds, e.Level)
+	}))
+
+	var defaults int
+	if shock >= 1 {
+		defaults = system.StressTest(cfg.Trigger)
+	} else {
+		defaults = system.Shock(nil, map[string]float64{cfg.Trigger: shock * system.Banks[cfg.Trigger].Balance})
+	}
+
+	return Result{
+		Topology: topology,
+		Params:   params,
+		Defaults: defaults,
+		Loss:     system.TotalLoss(base.Banks),
+		Rounds:   rounds,
+		Model:    system.Model.String(),
+		Order:    system.Order.String(),
+		Update:   system.Update.String(),
+	}
+}
+
+// product возвращает декартово произведение значений осей
+func product(grid []Axis) [][]float64 {
+	points := [][]float64{{}}
+	for _, axis := range grid {
+		next := make([][]float64, 0, len(points)*len(axis.Values))
+		for _, point := range points {
+			for _, value := range axis.Values {
+				extended := append(append([]float64(nil), point...), value)
+				next = append(next, extended)
+			}
+		}
+		points = next
+	}
+	return points
+}
+
+// WriteCSV записывает результаты перебора в CSV: по строке на каждую точку и топологию
+func WriteCSV(w io.Writer, grid []Axis, results []Result) error {
+	writer := csv.NewWriter(w)
+
+	header := []string{"topology"}
+	for _, axis := range grid {
+		header = append(header, axis.Name)
+	}
+	header = append(header, "defaults", "loss", "rounds", "model", "order", "update")
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		record := []string{result.Topology}
+		for _, value := range result.Params {
+			record = append(record, strconv.FormatFloat(value, 'g', -1, 64))
+		}
+		record = append(record,
+			strconv.Itoa(result.Defaults),
+			strconv.FormatFloat(result.Loss, 'f', 2, 64),
+			strconv.Itoa(result.Rounds),
+			result.Model,
+			result.Order,
+			result.Update,
+		)
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
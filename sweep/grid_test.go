@@ -0,0 +1,176 @@
+package sweep
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nlypage/BankSystemVisualize/contagion"
+	"github.com/nlypage/BankSystemVisualize/topology"
+)
+
+// gridConfig возвращает конфигурацию перебора для полной и кольцевой топологий со случайным порядком обработки
+func gridConfig(workers int) Config {
+	topologies := map[string]*contagion.BankSystem{
+		"full": {EnablePanic: true, Order: contagion.Order{Kind: contagion.OrderRandom}, Banks: topology.Complete(5, topology.Uniform(1000, 5000))},
+		"ring": {EnablePanic: true, Order: contagion.Order{Kind: contagion.OrderRandom}, Banks: topology.Ring(5, topology.Uniform(1000, 5000))},
+	}
+	return Config{
+		Grid: []Axis{
+			Range("p", 0, 1, 4),
+			Range("lambda", 0, 1, 4),
+			{Name: "seed", Values: []float64{1, 2}},
+		},
+		Topologies: topologies,
+		Trigger:    "1",
+		Workers:    workers,
+	}
+}
+
+func TestRunIsDeterministic(t *testing.T) {
+	want, err := Run(gridConfig(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(want) != 5*5*2*2 {
+		t.Fatalf("got %d results, want %d", len(want), 5*5*2*2)
+	}
+	for i, result := range want {
+		if topology := []string{"full", "ring"}[i%2]; result.Topology != topology {
+			t.Fatalf("result %d: topology %s, want %s", i, result.Topology, topology)
+		}
+	}
+
+	for _, workers := range []int{2, 8} {
+		got, err := Run(gridConfig(workers))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%d workers: results differ from a single worker", workers)
+		}
+	}
+}
+
+func TestRunRejectsGrid(t *testing.T) {
+	tests := []struct {
+		name string
+		grid []Axis
+	}{
+		{"unknown", []Axis{{Name: "q", Values: []float64{1}}}},
+		{"duplicate", []Axis{{Name: "p", Values: []float64{1}}, {Name: "p", Values: []float64{0}}}},
+		{"overlap", []Axis{{Name: "lambda_c", Values: []float64{1}}, {Name: "lambda", Values: []float64{0}}}},
+		{"empty", []Axis{{Name: "p"}}},
+	}
+	for _, tt := range tests {
+		cfg := gridConfig(1)
+		cfg.Grid = tt.grid
+		if _, err := Run(cfg); err == nil {
+			t.Errorf("%s: no error", tt.name)
+		}
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	grid := []Axis{{Name: "p", Values: []float64{0.5}}, {Name: "lambda", Values: []float64{0.25}}}
+	results := []Result{
+		{Topology: "full", Params: []float64{0.5, 0.25}, Defaults: 3, Loss: 1234.5678, Rounds: 2, Model: "cascade", Order: "name", Update: "sequential"},
+		{Topology: "ring", Params: []float64{0.5, 0.25}, Defaults: 0, Loss: 0, Rounds: 0, Model: "cascade", Order: "random(1)", Update: "synchronous"},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, grid, results); err != nil {
+		t.Fatal(err)
+	}
+	want := "topology,p,lambda,defaults,loss,rounds,model,order,update\n" +
+		"full,0.5,0.25,3,1234.57,2,cascade,name,sequential\n" +
+		"ring,0.5,0.25,0,0.00,0,cascade,random(1),synchronous\n"
+	if buf.String() != want {
+		t.Errorf("CSV:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestParseAxis(t *testing.T) {
+	tests := []struct {
+		spec   string
+		want   Axis
+		hasErr bool
+	}{
+		{spec: "p=0:1:0.25", want: Axis{Name: "p", Values: []float64{0, 0.25, 0.5, 0.75, 1}}},
+		{spec: "lambda=0.1:0.3:0.1", want: Axis{Name: "lambda", Values: []float64{0.1, 0.2, 0.30000000000000004}}},
+		{spec: "capital_ratio=0:0.05:0.02", want: Axis{Name: "capital_ratio", Values: []float64{0, 0.02, 0.04}}},
+		{spec: "seed=1, 2,3", want: Axis{Name: "seed", Values: []float64{1, 2, 3}}},
+		{spec: " shock =0.5", want: Axis{Name: "shock", Values: []float64{0.5}}},
+		{spec: "q=1", hasErr: true},
+		{spec: "p", hasErr: true},
+		{spec: "p=", hasErr: true},
+		{spec: "p=0:1", hasErr: true},
+		{spec: "p=0:1:0", hasErr: true},
+		{spec: "p=1:0:0.1", hasErr: true},
+		{spec: "p=0,x", hasErr: true},
+		{spec: "p=NaN", hasErr: true},
+		{spec: "p=0:Inf:1", hasErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseAxis(tt.spec)
+		if (err != nil) != tt.hasErr {
+			t.Errorf("ParseAxis(%q) error = %v, want error %t", tt.spec, err, tt.hasErr)
+			continue
+		}
+		if !tt.hasErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseAxis(%q) = %v, want %v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestRange(t *testing.T) {
+	axis := Range("p", 0.1, 1, 9)
+	if len(axis.Values) != 10 || axis.Values[0] != 0.1 || axis.Values[9] != 1 {
+		t.Errorf("Range(0.1, 1, 9) = %v", axis.Values)
+	}
+	if axis := Range("p", 0.5, 1, 0); !reflect.DeepEqual(axis.Values, []float64{0.5}) {
+		t.Errorf("Range with zero steps = %v, want [0.5]", axis.Values)
+	}
+}
+
+// Перебор полной и кольцевой топологий в режиме по умолчанию совпадает с выводом исходной программы cmd/raw.
+// Сетка та же, что у команды sweep по умолчанию. Цикл cmd/raw накапливал p += 0.1 и вместо 1 доходил
+// до 0.9999999999999999: тогда баланс банка 3 в кольце при lambda = 0.7 равен -4.5e-13, и cmd/raw считал
+// его банкротом. С точными значениями сетки баланс ровно 0, поэтому точка p = 1, lambda = 0.7 есть в выводе
+func TestDefaultGridMatchesBaseline(t *testing.T) {
+	results, err := Run(Config{
+		Grid: []Axis{Range("p", 0.1, 1, 9), Range("lambda", 0.1, 1, 9)},
+		Topologies: map[string]*contagion.BankSystem{
+			"full": {EnablePanic: true, Banks: topology.Complete(5, topology.Uniform(1000, 5000))},
+			"ring": {EnablePanic: true, Banks: topology.Ring(5, topology.Uniform(1000, 5000))},
+		},
+		Trigger: "1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for i := 0; i < len(results); i += 2 {
+		full, ring := results[i], results[i+1]
+		if ring.Defaults < full.Defaults {
+			got = append(got, fmt.Sprintf("p: %f, lambda: %f", full.Params[0], full.Params[1]))
+		}
+	}
+	want := []string{
+		"p: 0.600000, lambda: 0.500000",
+		"p: 0.700000, lambda: 0.500000",
+		"p: 0.800000, lambda: 0.500000",
+		"p: 0.800000, lambda: 0.600000",
+		"p: 0.900000, lambda: 0.500000",
+		"p: 0.900000, lambda: 0.600000",
+		"p: 1.000000, lambda: 0.500000",
+		"p: 1.000000, lambda: 0.600000",
+		"p: 1.000000, lambda: 0.700000",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("points where the ring is more stable:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
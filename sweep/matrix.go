@@ -0,0 +1,78 @@
+package sweep
+
+import "fmt"
+
+// Matrix собирает значения метрики одной топологии в матрицу по осям xAxis и yAxis.
+// Для остальных осей сетки берутся точки, где параметр равен fixed[имя оси],
+// а если значение не задано, то первому значению оси
+func Matrix(grid []Axis, results []Result, topology, xAxis, yAxis string, fixed map[string]float64,
+	value func(Result) float64) (xs, ys []float64, values [][]float64, err error) {
+	xIndex, yIndex := -1, -1
+	want := make([]float64, len(grid))
+	for i, axis := range grid {
+		switch axis.Name {
+		case xAxis:
+			xIndex = i
+			xs = axis.Values
+		case yAxis:
+			yIndex = i
+			ys = axis.Values
+		default:
+			if v, exists := fixed[axis.Name]; exists {
+				want[i] = v
+			} else {
+				want[i] = axis.Values[0]
+			}
+		}
+	}
+	if xIndex < 0 || yIndex < 0 {
+		return nil, nil, nil, fmt.Errorf("в сетке нет осей %q и %q", xAxis, yAxis)
+	}
+
+	values = make([][]float64, len(ys))
+	filled := make([][]bool, len(ys))
+	for row := range values {
+		values[row] = make([]float64, len(xs))
+		filled[row] = make([]bool, len(xs))
+	}
+
+	for _, result := range results {
+		if result.Topology != topology || !matches(result.Params, want, xIndex, yIndex) {
+			continue
+		}
+		row := indexOf(ys, result.Params[yIndex])
+		col := indexOf(xs, result.Params[xIndex])
+		values[row][col] = value(result)
+		filled[row][col] = true
+	}
+
+	for row := range filled {
+		for col := range filled[row] {
+			if !filled[row][col] {
+				return nil, nil, nil, fmt.Errorf("нет результата топологии %q для %s = %g, %s = %g",
+					topology, xAxis, xs[col], yAxis, ys[row])
+			}
+		}
+	}
+	return xs, ys, values, nil
+}
+
+// matches проверяет, что параметры точки совпадают с want везде, кроме осей диаграммы
+func matches(params, want []float64, xIndex, yIndex int) bool {
+	for i := range params {
+		if i != xIndex && i != yIndex && params[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// indexOf возвращает индекс значения на оси
+func indexOf(values []float64, value float64) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
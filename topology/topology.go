@@ -0,0 +1,211 @@
+// Package topology генерирует межбанковские сети типовых топологий для N банков
+package topology
+
+import (
+	"math/rand"
+	"sort"
+	"strconv"
+
+	"github.com/nlypage/BankSystemVisualize/contagion"
+)
+
+// Rules правила заполнения балансов и требований банков.
+// Банки нумеруются с нуля, имя банка i - строка i+1, как в исходных сценариях
+type Rules struct {
+	Balance       func(i int) float64 // Баланс банка (X)
+	TotalExposure func(i int) float64 // Сумма требований банка (Y), делится поровну между его партнерами
+}
+
+// Uniform правила, при которых у каждого банка баланс X и сумма требований Y
+func Uniform(X, Y float64) Rules {
+	return Rules{
+		Balance:       func(int) float64 { return X },
+		TotalExposure: func(int) float64 { return Y },
+	}
+}
+
+// Name возвращает имя i-го банка
+func Name(i int) string {
+	return strconv.Itoa(i + 1)
+}
+
+// graph неориентированный граф связей между банками
+type graph []map[int]bool
+
+// newGraph создает граф из n банков без связей
+func newGraph(n int) graph {
+	g := make(graph, n)
+	for i := range g {
+		g[i] = make(map[int]bool)
+	}
+	return g
+}
+
+// link связывает банки i и j взаимными требованиями
+func (g graph) link(i, j int) {
+	if i == j {
+		return
+	}
+	g[i][j] = true
+	g[j][i] = true
+}
+
+// unlink удаляет связь между банками i и j
+func (g graph) unlink(i, j int) {
+	delete(g[i], j)
+	delete(g[j], i)
+}
+
+// banks строит банки по графу: требования банка делятся поровну между его партнерами
+func (g graph) banks(rules Rules) map[string]contagion.Bank {
+	banks := make(map[string]contagion.Bank, len(g))
+	for i, neighbours := range g {
+		dependencies := make(map[string]float64, len(neighbours))
+		for j := range neighbours {
+			dependencies[Name(j)] = rules.TotalExposure(i) / float64(len(neighbours))
+		}
+		banks[Name(i)] = contagion.Bank{
+			Balance:      rules.Balance(i),
+			Dependencies: dependencies,
+		}
+	}
+	return banks
+}
+
+// Complete полный граф: каждый банк связан со всеми остальными
+func Complete(n int, rules Rules) map[string]contagion.Bank {
+	g := newGraph(n)
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			g.link(i, j)
+		}
+	}
+	return g.banks(rules)
+}
+
+// Ring кольцо: каждый банк связан с предыдущим и следующим
+func Ring(n int, rules Rules) map[string]contagion.Bank {
+	g := newGraph(n)
+	for i := 0; i < n; i++ {
+		g.link(i, (i+1)%n)
+	}
+	return g.banks(rules)
+}
+
+// Star звезда: банк "1" связан со всеми остальными, остальные только с ним
+func Star(n int, rules Rules) map[string]contagion.Bank {
+	g := newGraph(n)
+	for i := 1; i < n; i++ {
+		g.link(0, i)
+	}
+	return g.banks(rules)
+}
+
+// CorePeriphery ядро из core банков, связанных полным графом, и periphery периферийных банков,
+// каждый из которых по очереди привязан к одному банку ядра
+func CorePeriphery(core, periphery int, rules Rules) map[string]contagion.Bank {
+	g := newGraph(core + periphery)
+	for i := 0; i < core; i++ {
+		for j := i + 1; j < core; j++ {
+			g.link(i, j)
+		}
+	}
+	if core > 0 {
+		for i := 0; i < periphery; i++ {
+			g.link(core+i, i%core)
+		}
+	}
+	return g.banks(rules)
+}
+
+// ErdosRenyi случайный граф: каждая пара банков связана с вероятностью prob
+func ErdosRenyi(n int, prob float64, rules Rules, seed int64) map[string]contagion.Bank {
+	rng := rand.New(rand.NewSource(seed))
+	g := newGraph(n)
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			if rng.Float64() < prob {
+				g.link(i, j)
+			}
+		}
+	}
+	return g.banks(rules)
+}
+
+// BarabasiAlbert безмасштабный граф: начинается с полного графа из m+1 банков,
+// каждый новый банк связывается с m банками с вероятностью, пропорциональной их степени
+func BarabasiAlbert(n, m int, rules Rules, seed int64) map[string]contagion.Bank {
+	rng := rand.New(rand.NewSource(seed))
+	g := newGraph(n)
+	initial := min(n, m+1)
+	for i := 0; i < initial; i++ {
+		for j := i + 1; j < initial; j++ {
+			g.link(i, j)
+		}
+	}
+
+	// Каждый конец каждой связи - одна запись, выбор из списка дает предпочтительное присоединение
+	ends := make([]int, 0)
+	for i := 0; i < initial; i++ {
+		for j := range g[i] {
+			if i < j {
+				ends = append(ends, i, j)
+			}
+		}
+	}
+	sort.Ints(ends)
+
+	for i := initial; i < n; i++ {
+		targets := make(map[int]bool, m)
+		for len(targets) < min(m, i) {
+			if len(ends) == 0 {
+				targets[rng.Intn(i)] = true
+				continue
+			}
+			targets[ends[rng.Intn(len(ends))]] = true
+		}
+
+		chosen := make([]int, 0, len(targets))
+		for target := range targets {
+			chosen = append(chosen, target)
+		}
+		sort.Ints(chosen)
+		for _, target := range chosen {
+			g.link(i, target)
+			ends = append(ends, i, target)
+		}
+	}
+	return g.banks(rules)
+}
+
+// WattsStrogatz граф «тесного мира»: кольцо, где каждый банк связан с k ближайшими соседями
+// (k/2 с каждой стороны), после чего каждая связь с вероятностью beta перенаправляется на случайный банк
+func WattsStrogatz(n, k int, beta float64, rules Rules, seed int64) map[string]contagion.Bank {
+	rng := rand.New(rand.NewSource(seed))
+	g := newGraph(n)
+	for i := 0; i < n; i++ {
+		for step := 1; step <= k/2; step++ {
+			g.link(i, (i+step)%n)
+		}
+	}
+
+	for step := 1; step <= k/2; step++ {
+		for i := 0; i < n; i++ {
+			j := (i + step) % n
+			if !g[i][j] || rng.Float64() >= beta {
+				continue
+			}
+			// Перенаправляем связь, избегая петель и повторных связей
+			if len(g[i]) >= n-1 {
+				continue
+			}
+			target := rng.Intn(n)
+			for target == i || g[i][target] {
+				target = rng.Intn(n)
+			}
+			g.unlink(i, j)
+			g.link(i, target)
+		}
+	}
+	return g.banks(rules)
+}
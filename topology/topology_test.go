@@ -0,0 +1,110 @@
+package topology
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nlypage/BankSystemVisualize/contagion"
+)
+
+// Кольцо и полный граф совпадают с системами, которые исходная программа cmd/raw задавала вручную
+func TestBaselineTopologies(t *testing.T) {
+	X, Y := 1000.0, 5000.0
+	full := map[string]contagion.Bank{
+		"1": {Balance: X, Dependencies: map[string]float64{"2": Y / 4, "3": Y / 4, "4": Y / 4, "5": Y / 4}},
+		"2": {Balance: X, Dependencies: map[string]float64{"1": Y / 4, "3": Y / 4, "4": Y / 4, "5": Y / 4}},
+		"3": {Balance: X, Dependencies: map[string]float64{"1": Y / 4, "2": Y / 4, "4": Y / 4, "5": Y / 4}},
+		"4": {Balance: X, Dependencies: map[string]float64{"1": Y / 4, "2": Y / 4, "3": Y / 4, "5": Y / 4}},
+		"5": {Balance: X, Dependencies: map[string]float64{"1": Y / 4, "2": Y / 4, "3": Y / 4, "4": Y / 4}},
+	}
+	circle := map[string]contagion.Bank{
+		"1": {Balance: X, Dependencies: map[string]float64{"2": Y / 2, "5": Y / 2}},
+		"2": {Balance: X, Dependencies: map[string]float64{"1": Y / 2, "3": Y / 2}},
+		"3": {Balance: X, Dependencies: map[string]float64{"2": Y / 2, "4": Y / 2}},
+		"4": {Balance: X, Dependencies: map[string]float64{"3": Y / 2, "5": Y / 2}},
+		"5": {Balance: X, Dependencies: map[string]float64{"1": Y / 2, "4": Y / 2}},
+	}
+	if got := Complete(5, Uniform(X, Y)); !reflect.DeepEqual(got, full) {
+		t.Errorf("Complete(5) = %v, want %v", got, full)
+	}
+	if got := Ring(5, Uniform(X, Y)); !reflect.DeepEqual(got, circle) {
+		t.Errorf("Ring(5) = %v, want %v", got, circle)
+	}
+}
+
+// degrees возвращает степень каждого банка и проверяет, что связи взаимны
+func degrees(t *testing.T, banks map[string]contagion.Bank) map[string]int {
+	t.Helper()
+	result := make(map[string]int, len(banks))
+	for name, bank := range banks {
+		result[name] = len(bank.Dependencies)
+		for partner := range bank.Dependencies {
+			if _, exists := banks[partner].Dependencies[name]; !exists {
+				t.Errorf("link %s → %s has no reverse link", name, partner)
+			}
+		}
+	}
+	return result
+}
+
+func TestDegrees(t *testing.T) {
+	rules := Uniform(1000, 5000)
+	tests := []struct {
+		name  string
+		banks map[string]contagion.Bank
+		want  map[string]int
+	}{
+		{"star", Star(5, rules), map[string]int{"1": 4, "2": 1, "3": 1, "4": 1, "5": 1}},
+		{"core-periphery", CorePeriphery(3, 4, rules), map[string]int{"1": 4, "2": 3, "3": 3, "4": 1, "5": 1, "6": 1, "7": 1}},
+		{"watts-strogatz without rewiring", WattsStrogatz(6, 4, 0, rules, 1), map[string]int{"1": 4, "2": 4, "3": 4, "4": 4, "5": 4, "6": 4}},
+	}
+	for _, tt := range tests {
+		if got := degrees(t, tt.banks); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: degrees = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRandomDegrees(t *testing.T) {
+	rules := Uniform(1000, 5000)
+	tests := []struct {
+		name      string
+		banks     map[string]contagion.Bank
+		links     int // Количество связей
+		minDegree int
+	}{
+		// Полный граф из m+1 = 3 банков и по m = 2 связи у каждого из остальных 17
+		{"barabasi-albert", BarabasiAlbert(20, 2, rules, 1), 3 + 17*2, 2},
+		// Перенаправление сохраняет количество связей n*k/2
+		{"watts-strogatz", WattsStrogatz(20, 4, 0.3, rules, 1), 20 * 4 / 2, 1},
+	}
+	for _, tt := range tests {
+		total := 0
+		for name, degree := range degrees(t, tt.banks) {
+			total += degree
+			if degree < tt.minDegree {
+				t.Errorf("%s: bank %s has degree %d, want at least %d", tt.name, name, degree, tt.minDegree)
+			}
+		}
+		if total != 2*tt.links {
+			t.Errorf("%s: %d links, want %d", tt.name, total/2, tt.links)
+		}
+	}
+}
+
+func TestSeedIsReproducible(t *testing.T) {
+	rules := Uniform(1000, 5000)
+	generators := map[string]func(seed int64) map[string]contagion.Bank{
+		"erdos-renyi":     func(seed int64) map[string]contagion.Bank { return ErdosRenyi(20, 0.2, rules, seed) },
+		"barabasi-albert": func(seed int64) map[string]contagion.Bank { return BarabasiAlbert(20, 2, rules, seed) },
+		"watts-strogatz":  func(seed int64) map[string]contagion.Bank { return WattsStrogatz(20, 4, 0.3, rules, seed) },
+	}
+	for name, generate := range generators {
+		if !reflect.DeepEqual(generate(42), generate(42)) {
+			t.Errorf("%s: different networks for the same seed", name)
+		}
+		if reflect.DeepEqual(generate(42), generate(43)) {
+			t.Errorf("%s: the network does not depend on the seed", name)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Package trace записывает события стресс-теста в формате JSON Lines: по одной записи на событие
+package trace
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/nlypage/BankSystemVisualize/contagion"
+)
+
+// Record одна запись трассы. Source - банк, из которого уходят средства (contagion.Event.Bank),
+// Target - банк, в сторону которого они уходят (contagion.Event.Counterparty)
+type Record struct {
+	Step         int      `json:"step"`  // Порядковый номер события в прогоне, начиная с 0
+	Level        int      `json:"level"` // Уровень каскада
+	Type         string   `json:"type"`
+	Source       string   `json:"source"`
+	Target       string   `json:"target,omitempty"`
+	Cause        string   `json:"cause,omitempty"`
+	Amount       float64  `json:"amount"`
+	DefaultKind  string   `json:"default_kind,omitempty"`
+	SourceBefore float64  `json:"source_balance_before"`
+	SourceAfter  float64  `json:"source_balance_after"`
+	TargetBefore *float64 `json:"target_balance_before,omitempty"`
+	TargetAfter  *float64 `json:"target_balance_after,omitempty"`
+}
+
+// Writer наблюдатель, записывающий трассу. Баланс "до" - баланс банка после предыдущего события
+// с его участием, а для первого события - баланс на момент создания Writer
+type Writer struct {
+	encoder  *json.Encoder
+	balances map[string]float64
+	step     int
+	err      error
+}
+
+// New создает трассу для системы. Вызывается до запуска стресс-теста, чтобы запомнить исходные балансы
+func New(w io.Writer, system *contagion.BankSystem) *Writer {
+	balances := make(map[string]float64, len(system.Banks))
+	for name, bank := range system.Banks {
+		balances[name] = bank.Balance
+	}
+	return &Writer{encoder: json.NewEncoder(w), balances: balances}
+}
+
+// OnEvent записывает событие. После первой ошибки записи остальные события пропускаются
+func (t *Writer) OnEvent(e contagion.Event) {
+	if t.err != nil {
+		return
+	}
+
+	record := Record{
+		Step:         t.step,
+		Level:        e.Level,
+		Type:         e.Type.String(),
+		Source:       e.Bank,
+		Target:       e.Counterparty,
+		Cause:        e.Cause,
+		Amount:       e.Amount,
+		SourceBefore: t.balances[e.Bank],
+		SourceAfter:  e.Balance,
+	}
+	if e.Type == contagion.EventDefault {
+		record.DefaultKind = e.DefaultKind.String()
+	}
+	t.balances[e.Bank] = e.Balance
+	if e.Counterparty != "" {
+		before, after := t.balances[e.Counterparty], e.CounterpartyBalance
+		record.TargetBefore, record.TargetAfter = &before, &after
+		t.balances[e.Counterparty] = after
+	}
+	t.step++
+
+	t.err = t.encoder.Encode(record)
+}
+
+// Err возвращает первую ошибку записи трассы
+func (t *Writer) Err() error {
+	return t.err
+}
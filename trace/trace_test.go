@@ -0,0 +1,137 @@
+package trace
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/nlypage/BankSystemVisualize/contagion"
+)
+
+// float возвращает указатель на значение для полей баланса контрагента
+func float(v float64) *float64 {
+	return &v
+}
+
+// decode читает записи трассы, не допуская неизвестных полей
+func decode(t *testing.T, r io.Reader) []Record {
+	t.Helper()
+	var records []Record
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	for {
+		var record Record
+		err := decoder.Decode(&record)
+		if err == io.EOF {
+			return records
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	// Цепочка A → B → C: каждый банк вложил 100 в следующего и имеет баланс 10
+	system := &contagion.BankSystem{
+		LambdaC: 0.5,
+		LambdaF: 0.5,
+		Banks: map[string]contagion.Bank{
+			"A": {Balance: 10, Dependencies: map[string]float64{"B": 100}},
+			"B": {Balance: 10, Dependencies: map[string]float64{"C": 100}},
+			"C": {Balance: 10},
+		},
+	}
+	var buf bytes.Buffer
+	writer := New(&buf, system)
+	system.Subscribe(writer)
+	system.StressTest("A")
+	if err := writer.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	records := decode(t, &buf)
+	want := []Record{
+		{Step: 0, Level: 0, Type: "default", Source: "A", DefaultKind: "initial", SourceBefore: 10, SourceAfter: -1},
+		{Step: 1, Level: 0, Type: "funding_shock", Source: "B", Target: "A", Cause: "A", Amount: 50,
+			SourceBefore: 10, SourceAfter: -40, TargetBefore: float(-1), TargetAfter: float(-1)},
+		{Step: 2, Level: 1, Type: "default", Source: "B", DefaultKind: "solvency", SourceBefore: -40, SourceAfter: -40},
+		{Step: 3, Level: 1, Type: "funding_shock", Source: "C", Target: "B", Cause: "B", Amount: 50,
+			SourceBefore: 10, SourceAfter: -40, TargetBefore: float(-40), TargetAfter: float(-40)},
+		{Step: 4, Level: 2, Type: "default", Source: "C", DefaultKind: "solvency", SourceBefore: -40, SourceAfter: -40},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("records = %+v, want %+v", records, want)
+	}
+}
+
+func TestRoundTripLosses(t *testing.T) {
+	// Та же цепочка, но вместо банкротства B и C получают частичные потери: C теряет больше капитала
+	// и банкротится, B остается с балансом 6, который затем снижает кредитный шок
+	system := &contagion.BankSystem{
+		LambdaC: 0.5,
+		LambdaF: 0.5,
+		Banks: map[string]contagion.Bank{
+			"A": {Balance: 10, Dependencies: map[string]float64{"B": 100}},
+			"B": {Balance: 10, Dependencies: map[string]float64{"C": 100}},
+			"C": {Balance: 10},
+		},
+	}
+	var buf bytes.Buffer
+	writer := New(&buf, system)
+	system.Subscribe(writer)
+	system.Shock(nil, map[string]float64{"B": 4, "C": 15})
+	if err := writer.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Record{
+		{Step: 0, Level: 0, Type: "initial_loss", Source: "B", Amount: 4, SourceBefore: 10, SourceAfter: 6},
+		{Step: 1, Level: 0, Type: "initial_loss", Source: "C", Amount: 15, SourceBefore: 10, SourceAfter: -5},
+		{Step: 2, Level: 0, Type: "default", Source: "C", DefaultKind: "solvency", SourceBefore: -5, SourceAfter: -5},
+		{Step: 3, Level: 0, Type: "credit_shock", Source: "B", Target: "C", Cause: "C", Amount: 50,
+			SourceBefore: 6, SourceAfter: -44, TargetBefore: float(-5), TargetAfter: float(-5)},
+		{Step: 4, Level: 1, Type: "default", Source: "B", DefaultKind: "solvency", SourceBefore: -44, SourceAfter: -44},
+		{Step: 5, Level: 1, Type: "credit_shock", Source: "A", Target: "B", Cause: "B", Amount: 50,
+			SourceBefore: 10, SourceAfter: -40, TargetBefore: float(-44), TargetAfter: float(-44)},
+		{Step: 6, Level: 2, Type: "default", Source: "A", DefaultKind: "solvency", SourceBefore: -40, SourceAfter: -40},
+	}
+	if records := decode(t, &buf); !reflect.DeepEqual(records, want) {
+		t.Errorf("records = %+v, want %+v", records, want)
+	}
+}
+
+// failingWriter возвращает ошибку при каждой записи
+type failingWriter struct {
+	writes int
+}
+
+func (w *failingWriter) Write([]byte) (int, error) {
+	w.writes++
+	return 0, errors.New("disk full")
+}
+
+func TestWriteErrorStopsTrace(t *testing.T) {
+	system := &contagion.BankSystem{
+		LambdaF: 1,
+		Banks: map[string]contagion.Bank{
+			"A": {Balance: 10},
+			"B": {Balance: 10, Dependencies: map[string]float64{"A": 100}},
+		},
+	}
+	out := &failingWriter{}
+	writer := New(out, system)
+	system.Subscribe(writer)
+	system.StressTest("A")
+
+	if writer.Err() == nil {
+		t.Fatal("write error is not reported")
+	}
+	if out.writes != 1 {
+		t.Errorf("writes after the first error: %d, want 1 in total", out.writes)
+	}
+}